@@ -0,0 +1,68 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/gustavooferreira/wcrawler/internal/analytics"
+	"github.com/spf13/cobra"
+)
+
+func newAnalyzeCmd() *cobra.Command {
+	var (
+		inputFilePath string
+		format        string
+		top           uint
+		samehost      bool
+		writeback     bool
+	)
+
+	analyzeCmd := &cobra.Command{
+		Use:   "analyze",
+		Short: "Compute graph metrics (PageRank, HITS, degrees, components) over the crawled data",
+		Long: "Compute graph metrics (PageRank, HITS, degrees, components) over the crawled data.\n" +
+			"Scores can be written back into the input file to be used by the view command.",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if format != "table" && format != "json" {
+				return fmt.Errorf("unsupported format %q (use 'table' or 'json')", format)
+			}
+
+			rm, err := loadRecords(inputFilePath)
+			if err != nil {
+				return err
+			}
+
+			opts := analytics.DefaultOptions()
+			opts.SameHost = samehost
+
+			report := analytics.Analyze(analytics.NewGraph(rm), opts)
+
+			if format == "json" {
+				encoder := json.NewEncoder(cmd.OutOrStdout())
+				encoder.SetIndent("", "    ")
+				err = encoder.Encode(report)
+			} else {
+				err = analytics.WriteTable(cmd.OutOrStdout(), report, int(top))
+			}
+			if err != nil {
+				return err
+			}
+
+			if writeback {
+				analytics.WriteBack(rm, report)
+				return saveRecords(inputFilePath, rm)
+			}
+
+			return nil
+		},
+	}
+
+	analyzeCmd.Flags().StringVarP(&inputFilePath, "input", "i", "./web_graph.json", "file containing the data")
+	analyzeCmd.Flags().StringVarP(&format, "format", "f", "table", "output format (table or json)")
+	analyzeCmd.Flags().UintVarP(&top, "top", "k", 20, "number of pages to list in the table (0 means all)")
+	analyzeCmd.Flags().BoolVarP(&samehost, "samehost", "z", false, "only check reachability of pages in the seed's host")
+	analyzeCmd.Flags().BoolVarP(&writeback, "writeback", "w", false, "write scores back into the input file")
+
+	return analyzeCmd
+}
@@ -0,0 +1,63 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/gustavooferreira/wcrawler"
+	"github.com/spf13/cobra"
+)
+
+func newDiffCmd() *cobra.Command {
+	var (
+		format           string
+		failonregression bool
+	)
+
+	diffCmd := &cobra.Command{
+		Use:   "diff OLD_FILE NEW_FILE",
+		Short: "Compare two saved crawls and report what changed",
+		Long: "Compare two saved crawls and report what changed.\n" +
+			"Pages are matched by URL.",
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if format != "text" && format != "json" {
+				return fmt.Errorf("unsupported format %q (use 'text' or 'json')", format)
+			}
+
+			oldRM, err := loadRecords(args[0])
+			if err != nil {
+				return err
+			}
+
+			newRM, err := loadRecords(args[1])
+			if err != nil {
+				return err
+			}
+
+			diff := wcrawler.DiffRecords(oldRM, newRM)
+
+			if format == "json" {
+				encoder := json.NewEncoder(cmd.OutOrStdout())
+				encoder.SetIndent("", "    ")
+				err = encoder.Encode(diff)
+			} else {
+				err = diff.WriteText(cmd.OutOrStdout())
+			}
+			if err != nil {
+				return err
+			}
+
+			if failonregression && diff.HasRegressions() {
+				return fmt.Errorf("%d new errors found", len(diff.NewErrors))
+			}
+
+			return nil
+		},
+	}
+
+	diffCmd.Flags().StringVarP(&format, "format", "f", "text", "output format (text or json)")
+	diffCmd.Flags().BoolVarP(&failonregression, "failonregression", "x", false, "exit with a non-zero code if there are new errors")
+
+	return diffCmd
+}
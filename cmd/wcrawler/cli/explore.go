@@ -1,26 +1,48 @@
 package cli
 
 import (
+	"encoding/json"
+	"fmt"
 	"net/http"
 	"os"
 	"time"
 
+	"github.com/gosuri/uilive"
 	"github.com/gustavooferreira/wcrawler"
+	"github.com/gustavooferreira/wcrawler/internal/graph"
 	"github.com/spf13/cobra"
+	"golang.org/x/term"
 )
 
+// summaryTopPages is the number of pages listed as slowest, largest and deepest in the crawl summary.
+const summaryTopPages = 10
+
+// summaryEvent represents the crawl summary written after the progress events in the 'json' progress mode.
+type summaryEvent struct {
+	Type    string                `json:"type"`
+	Summary wcrawler.CrawlSummary `json:"summary"`
+}
+
 func newExploreCmd() *cobra.Command {
 	var (
-		filePath        string
-		nostats         bool
-		showerrors      bool
-		workers         uint
-		timeout         uint
-		retry           uint
-		depth           uint
-		stayinsubdomain bool
-		treemode        bool
-		client          *http.Client
+		filePath         string
+		nostats          bool
+		showerrors       bool
+		workers          uint
+		timeout          uint
+		retry            uint
+		depth            uint
+		stayinsubdomain  bool
+		treemode         bool
+		metricsaddr      string
+		controladdr      string
+		apiaddr          string
+		liveviewaddr     string
+		statsfile        string
+		reportfile       string
+		progress         string
+		progressinterval time.Duration
+		client           *http.Client
 	)
 
 	exploreCmd := &cobra.Command{
@@ -32,6 +54,17 @@ func newExploreCmd() *cobra.Command {
 		RunE: func(cmd *cobra.Command, args []string) error {
 			url := args[0]
 
+			if reportfile != "" {
+				if _, err := reportFormat(reportfile); err != nil {
+					return err
+				}
+			}
+
+			mode, err := progressMode(progress, nostats)
+			if err != nil {
+				return err
+			}
+
 			client = &http.Client{
 				Timeout: time.Second * time.Duration(timeout),
 			}
@@ -43,18 +76,162 @@ func newExploreCmd() *cobra.Command {
 
 			defer f.Close()
 
+			var sinks []wcrawler.StatsManager
+			var statsWriter *uilive.Writer
+			var dashboard *wcrawler.StatsDashboard
+			restoreTerminal := func() {}
+
+			switch mode {
+			case "tty":
+				statsWriter = uilive.New()
+				statsWriter.Out = cmd.OutOrStdout()
+				sinks = append(sinks, wcrawler.NewStatsCLIOutWriter(statsWriter, showerrors, int(workers), int(depth)))
+			case "dashboard":
+				dashboard = wcrawler.NewStatsDashboard(os.Stdout, terminalSize, int(workers), int(depth))
+				sinks = append(sinks, dashboard)
+			case "plain", "json":
+				var format wcrawler.ProgressFormat
+				format.Parse(mode)
+				sinks = append(sinks, wcrawler.NewStatsProgressWriter(cmd.OutOrStdout(), format, progressinterval,
+					int(workers), int(depth)))
+			}
+
+			if metricsaddr != "" {
+				metrics := wcrawler.NewStatsPrometheus(int(workers), int(depth))
+				sinks = append(sinks, metrics)
+
+				ln, err := listen(metricsaddr)
+				if err != nil {
+					return err
+				}
+
+				mux := http.NewServeMux()
+				mux.Handle("/metrics", metrics)
+				server := &http.Server{Handler: mux}
+				go server.Serve(ln)
+				defer server.Close()
+			}
+
+			var api *wcrawler.StatsAPI
+			if apiaddr != "" {
+				api = wcrawler.NewStatsAPI(int(workers), int(depth))
+				sinks = append(sinks, api)
+
+				ln, err := listen(apiaddr)
+				if err != nil {
+					return err
+				}
+
+				server := &http.Server{Handler: api}
+				go server.Serve(ln)
+				// Let the events streams send their last event
+				defer shutdown(server)
+			}
+
+			if statsfile != "" {
+				sf, err := os.Create(statsfile)
+				if err != nil {
+					return err
+				}
+				defer sf.Close()
+
+				sinks = append(sinks, wcrawler.NewStatsJSONWriter(sf, int(workers), int(depth)))
+			}
+
 			connector := wcrawler.NewWebClient(client)
-			c, err := wcrawler.NewCrawler(connector, url, int(retry), f, !nostats, showerrors, stayinsubdomain, treemode, int(workers), int(depth))
+			c, err := wcrawler.NewCrawler(connector, url, int(retry), f, wcrawler.NewMultiStatsManager(sinks...),
+				stayinsubdomain, treemode, int(workers), int(depth))
 			if err != nil {
 				return err
 			}
+
+			// Saved in the header of the output file, so the crawl can be told apart and run again
+			c.SetArgs(os.Args[1:])
+
+			if api != nil {
+				api.SetInspector(c)
+			}
+
+			if liveviewaddr != "" {
+				live, err := graph.NewLiveServer(graph.ViewerOptions{})
+				if err != nil {
+					return err
+				}
+				c.SetGraphListener(live)
+
+				ln, err := listen(liveviewaddr)
+				if err != nil {
+					return err
+				}
+
+				fmt.Fprintf(cmd.ErrOrStderr(), "Live view of the graph on %s\n", browserURL(ln.Addr()))
+
+				server := &http.Server{Handler: live}
+				go server.Serve(ln)
+				// Let the page get the 'finished' event
+				defer shutdown(server)
+			}
+
+			if controladdr != "" {
+				ln, err := listen(controladdr)
+				if err != nil {
+					return err
+				}
+
+				server := &http.Server{Handler: wcrawler.NewControlHandler(c)}
+				go server.Serve(ln)
+				defer server.Close()
+			}
+
+			if statsWriter != nil {
+				statsWriter.Start()
+			}
+
+			if dashboard != nil {
+				state, err := term.MakeRaw(int(os.Stdin.Fd()))
+				if err != nil {
+					return err
+				}
+
+				restoreTerminal = func() { term.Restore(int(os.Stdin.Fd()), state) }
+				defer restoreTerminal()
+
+				dashboard.SetController(c)
+				// Quitting stops the crawl, so the records found so far are still saved and summarised
+				go dashboard.HandleInput(os.Stdin, c.Stop)
+			}
+
 			c.Run()
+
+			if statsWriter != nil {
+				statsWriter.Stop() // flush and stop rendering
+			}
+			restoreTerminal()
+
+			summary := c.Summary(summaryTopPages)
+
+			switch mode {
+			case "tty", "dashboard", "plain":
+				fmt.Fprintf(cmd.OutOrStdout(), "\nCrawl summary\n\n")
+				err = summary.WriteTable(cmd.OutOrStdout())
+			case "json":
+				// Keep the output as JSON lines, following the progress events
+				err = json.NewEncoder(cmd.OutOrStdout()).Encode(summaryEvent{Type: "summary", Summary: summary})
+			}
+			if err != nil {
+				return err
+			}
+
+			if reportfile != "" {
+				return saveSummary(reportfile, summary)
+			}
+
 			return nil
 		},
 	}
 
 	exploreCmd.Flags().StringVarP(&filePath, "output", "o", "./web_graph.json", "file to save results")
-	exploreCmd.Flags().BoolVarP(&nostats, "nostats", "s", false, "don't show live stats")
+	exploreCmd.Flags().BoolVarP(&nostats, "nostats", "s", false, "don't show live stats nor the crawl summary (same as --progress=none)")
 	exploreCmd.Flags().BoolVarP(&showerrors, "showerrors", "e", false, "show list of errors")
 	exploreCmd.Flags().UintVarP(&workers, "workers", "w", 100, "number of workers making concurrent requests")
 	exploreCmd.Flags().UintVarP(&timeout, "timeout", "t", 10, "HTTP requests timeout in seconds")
@@ -62,6 +239,28 @@ func newExploreCmd() *cobra.Command {
 	exploreCmd.Flags().UintVarP(&depth, "depth", "d", 5, "depth of recursion")
 	exploreCmd.Flags().BoolVarP(&stayinsubdomain, "stayinsubdomain", "z", false, "follow links only in the same subdomain")
 	exploreCmd.Flags().BoolVarP(&treemode, "treemode", "m", false, "doesn't add links which would point back to known nodes")
+	exploreCmd.Flags().StringVar(&metricsaddr, "metrics-addr", "", "address to expose Prometheus metrics on (e.g. ':9090' or 'unix:/tmp/wcrawler-metrics.sock'), served at /metrics")
+	exploreCmd.Flags().StringVar(&controladdr, "control-addr", "", "address to serve the control endpoint on, to pause, resume and reconfigure the crawl "+
+		"(e.g. 'localhost:9091' or 'unix:/tmp/wcrawler.sock')")
+	exploreCmd.Flags().StringVar(&apiaddr, "api-addr", "", "address to serve the status API on, with the stats, errors, frontier, records and events of the crawl "+
+		"(e.g. 'localhost:9092' or 'unix:/tmp/wcrawler-api.sock')")
+	exploreCmd.Flags().StringVar(&liveviewaddr, "live-view", "", "address to serve a page showing the graph growing as the crawl runs on "+
+		"(e.g. '--live-view=localhost:8080', a random port on localhost if no address is given); needs the visualization library embedded in the build")
+	exploreCmd.Flags().Lookup("live-view").NoOptDefVal = "localhost:0"
+	exploreCmd.Flags().StringVar(&statsfile, "statsfile", "", "file to save the final stats in JSON format")
+	exploreCmd.Flags().StringVar(&progress, "progress", "auto", "progress output: tty, dashboard, plain, json or none ('auto' picks tty if stdout is a terminal, plain otherwise)")
+	exploreCmd.Flags().DurationVar(&progressinterval, "progress-interval", 2*time.Second, "time between progress lines in the plain and json modes")
+	exploreCmd.Flags().StringVar(&reportfile, "report", "", "file to save the crawl summary (JSON if it ends in '.json', Markdown if it ends in '.md')")
 
 	return exploreCmd
 }
+
+// terminalSize returns the size of the terminal on stdout, falling back to 80x24 if it's unknown.
+func terminalSize() (int, int) {
+	width, height, err := term.GetSize(int(os.Stdout.Fd()))
+	if err != nil || width == 0 || height == 0 {
+		return 80, 24
+	}
+
+	return width, height
+}
@@ -0,0 +1,41 @@
+package cli
+
+import (
+	"path/filepath"
+
+	"github.com/gustavooferreira/wcrawler"
+	"github.com/spf13/cobra"
+)
+
+func newMergeCmd() *cobra.Command {
+	var (
+		outputFilePath string
+	)
+
+	mergeCmd := &cobra.Command{
+		Use:   "merge FILE FILE [FILE...]",
+		Short: "Merge several crawl outputs into one graph",
+		Long: "Merge several crawl outputs into one graph.\n" +
+			"Records are re-indexed, edges are joined and conflicting status codes are resolved\n" +
+			"by keeping the most recent fetch. Each record keeps track of the crawls it came from.",
+		Args: cobra.MinimumNArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			rm := wcrawler.NewRecordManager()
+
+			for _, filePath := range args {
+				other, err := loadRecords(filePath)
+				if err != nil {
+					return err
+				}
+
+				rm.Merge(other, filepath.Base(filePath))
+			}
+
+			return saveRecords(outputFilePath, rm)
+		},
+	}
+
+	mergeCmd.Flags().StringVarP(&outputFilePath, "output", "o", "./web_graph.json", "file to save the merged results")
+
+	return mergeCmd
+}
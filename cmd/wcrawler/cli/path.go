@@ -0,0 +1,100 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/gustavooferreira/wcrawler"
+	"github.com/gustavooferreira/wcrawler/internal/analytics"
+	"github.com/spf13/cobra"
+)
+
+func newPathCmd() *cobra.Command {
+	var (
+		inputFilePath string
+		fromURL       string
+		toURL         string
+		count         uint
+		showanchors   bool
+	)
+
+	pathCmd := &cobra.Command{
+		Use:   "path",
+		Short: "Find the shortest click path(s) between two URLs in the crawled data",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if count == 0 {
+				return fmt.Errorf("the number of paths needs to be greater than 0")
+			}
+
+			rm, err := loadRecords(inputFilePath)
+			if err != nil {
+				return err
+			}
+
+			g := analytics.NewGraph(rm)
+
+			from, err := findPosition(g, fromURL)
+			if err != nil {
+				return err
+			}
+
+			to, err := findPosition(g, toURL)
+			if err != nil {
+				return err
+			}
+
+			paths := g.KShortestPaths(from, to, int(count))
+			if len(paths) == 0 {
+				return fmt.Errorf("no path found from %s to %s", fromURL, toURL)
+			}
+
+			out := cmd.OutOrStdout()
+			for i, p := range paths {
+				fmt.Fprintf(out, "Path %d (%d clicks):\n", i+1, len(p)-1)
+
+				for j, pos := range p {
+					fmt.Fprintf(out, "%4d. %s", j, g.Nodes[pos].URL)
+
+					if showanchors && j > 0 {
+						if text := g.AnchorText(p[j-1], pos); text != "" {
+							fmt.Fprintf(out, "  [%s]", text)
+						}
+					}
+
+					fmt.Fprintln(out)
+				}
+
+				if i != len(paths)-1 {
+					fmt.Fprintln(out)
+				}
+			}
+
+			return nil
+		},
+	}
+
+	pathCmd.Flags().StringVarP(&inputFilePath, "input", "i", "./web_graph.json", "file containing the data")
+	pathCmd.Flags().StringVarP(&fromURL, "from", "f", "", "URL where the path starts")
+	pathCmd.Flags().StringVarP(&toURL, "to", "t", "", "URL where the path ends")
+	pathCmd.Flags().UintVarP(&count, "count", "k", 1, "number of shortest paths to find")
+	pathCmd.Flags().BoolVarP(&showanchors, "showanchors", "a", false, "show the text of the link followed on each hop")
+	pathCmd.MarkFlagRequired("from")
+	pathCmd.MarkFlagRequired("to")
+
+	return pathCmd
+}
+
+// findPosition returns the position in the graph of the URL provided.
+func findPosition(g *analytics.Graph, rawURL string) (int, error) {
+	urlEntity, err := wcrawler.ExtractURL(rawURL)
+	if err != nil {
+		return 0, err
+	}
+
+	pos, ok := g.Position(urlEntity.Raw)
+	if !ok {
+		return 0, fmt.Errorf("URL %s not found in the crawled data", urlEntity.Raw)
+	}
+
+	return pos, nil
+}
@@ -1,6 +1,7 @@
 package cli
 
 import (
+	"github.com/gustavooferreira/wcrawler"
 	"github.com/spf13/cobra"
 )
 
@@ -8,6 +9,7 @@ func NewRootCmd() *cobra.Command {
 	rootCmd := &cobra.Command{
 		Use:          "wcrawler",
 		Short:        "A cli tool for crawling the web",
+		Version:      wcrawler.Version,
 		SilenceUsage: true,
 		// SilenceErrors: true,
 	}
@@ -15,7 +17,12 @@ func NewRootCmd() *cobra.Command {
 	// Init sub commands
 	exploreCmd := newExploreCmd()
 	viewCmd := newViewCmd()
+	analyzeCmd := newAnalyzeCmd()
+	pathCmd := newPathCmd()
+	diffCmd := newDiffCmd()
+	mergeCmd := newMergeCmd()
+	treeCmd := newTreeCmd()
 
-	rootCmd.AddCommand(exploreCmd, viewCmd)
+	rootCmd.AddCommand(exploreCmd, viewCmd, analyzeCmd, pathCmd, diffCmd, mergeCmd, treeCmd)
 	return rootCmd
 }
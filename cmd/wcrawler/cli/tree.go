@@ -0,0 +1,72 @@
+package cli
+
+import (
+	"os"
+
+	"github.com/gustavooferreira/wcrawler/internal/graph"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+func newTreeCmd() *cobra.Command {
+	var (
+		inputFilePath string
+		hosts         []string
+		htmlFilePath  string
+		levels        uint
+		printTree     bool
+	)
+
+	treeCmd := &cobra.Command{
+		Use:   "tree",
+		Short: "Show how the crawled pages are organised by URL path",
+		Long: "Show how the crawled pages are organised by URL path, with a tree per host.\n" +
+			"Every path lists the number of pages at or under it by status class, and the broken ones (4xx, 5xx or error).\n" +
+			"On a terminal the tree is browsed with the arrow keys, otherwise (or with --print) it's printed.",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			rm, err := loadRecords(inputFilePath)
+			if err != nil {
+				return err
+			}
+
+			elements, err := graph.Filter{Hosts: hosts}.Apply(rm, graph.NewElements(rm))
+			if err != nil {
+				return err
+			}
+
+			tree := graph.NewSiteTree(elements)
+
+			if htmlFilePath != "" {
+				f, err := os.Create(htmlFilePath)
+				if err != nil {
+					return err
+				}
+				defer f.Close()
+
+				return tree.WriteHTML(f)
+			}
+
+			if printTree || !term.IsTerminal(int(os.Stdin.Fd())) || !term.IsTerminal(int(os.Stdout.Fd())) {
+				return tree.WriteText(cmd.OutOrStdout(), int(levels))
+			}
+
+			state, err := term.MakeRaw(int(os.Stdin.Fd()))
+			if err != nil {
+				return err
+			}
+			defer term.Restore(int(os.Stdin.Fd()), state)
+
+			graph.NewTreeBrowser(tree, os.Stdout, terminalSize).Run(os.Stdin)
+			return nil
+		},
+	}
+
+	treeCmd.Flags().StringVarP(&inputFilePath, "input", "i", "./web_graph.json", "file containing the data")
+	treeCmd.Flags().StringSliceVar(&hosts, "host", nil, "only show pages on these hosts or their subdomains")
+	treeCmd.Flags().StringVar(&htmlFilePath, "html", "", "write the tree to this HTML file, with collapsible paths, instead of showing it")
+	treeCmd.Flags().UintVarP(&levels, "levels", "l", 2, "number of levels under the hosts printed (0 means all)")
+	treeCmd.Flags().BoolVarP(&printTree, "print", "p", false, "print the tree instead of browsing it, even on a terminal")
+
+	return treeCmd
+}
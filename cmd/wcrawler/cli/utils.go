@@ -0,0 +1,141 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/gustavooferreira/wcrawler"
+	"golang.org/x/term"
+)
+
+// loadRecords loads the crawled data from a file into a new RecordManager.
+func loadRecords(filePath string) (*wcrawler.RecordManager, error) {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	rm := wcrawler.NewRecordManager()
+	err = rm.LoadFromReader(f)
+	if err != nil {
+		return nil, err
+	}
+
+	return rm, nil
+}
+
+// saveRecords saves the records in the RecordManager to a file.
+func saveRecords(filePath string, rm *wcrawler.RecordManager) error {
+	f, err := os.Create(filePath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	return rm.SaveToWriter(f, true)
+}
+
+// reportFormat returns the format of the report based on the file extension (json or markdown).
+func reportFormat(filePath string) (string, error) {
+	switch filepath.Ext(filePath) {
+	case ".json":
+		return "json", nil
+	case ".md", ".markdown":
+		return "markdown", nil
+	default:
+		return "", fmt.Errorf("unsupported report file extension %q (use '.json' or '.md')", filepath.Ext(filePath))
+	}
+}
+
+// saveSummary saves the crawl summary to a file, in JSON or Markdown depending on the file extension.
+func saveSummary(filePath string, summary wcrawler.CrawlSummary) error {
+	format, err := reportFormat(filePath)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.Create(filePath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if format == "markdown" {
+		return summary.WriteMarkdown(f)
+	}
+
+	encoder := json.NewEncoder(f)
+	encoder.SetIndent("", "    ")
+	return encoder.Encode(summary)
+}
+
+// progressMode returns the progress mode to use (tty, dashboard, plain, json or none).
+// The 'auto' mode picks 'tty' if stdout is a terminal and 'plain' otherwise.
+// The 'dashboard' mode is never picked automatically and needs both stdin and stdout to be terminals.
+func progressMode(mode string, nostats bool) (string, error) {
+	if nostats {
+		return "none", nil
+	}
+
+	switch mode {
+	case "tty", "plain", "json", "none":
+		return mode, nil
+	case "dashboard":
+		if !term.IsTerminal(int(os.Stdin.Fd())) || !term.IsTerminal(int(os.Stdout.Fd())) {
+			return "", fmt.Errorf("the dashboard progress mode needs stdin and stdout to be terminals")
+		}
+		return mode, nil
+	case "auto":
+		if term.IsTerminal(int(os.Stdout.Fd())) {
+			return "tty", nil
+		}
+		return "plain", nil
+	default:
+		return "", fmt.Errorf("unsupported progress mode %q (use 'auto', 'tty', 'dashboard', 'plain', 'json' or 'none')", mode)
+	}
+}
+
+// listen listens on a TCP address, or on a Unix socket if the address starts with 'unix:'.
+func listen(addr string) (net.Listener, error) {
+	if path := strings.TrimPrefix(addr, "unix:"); path != addr {
+		// Remove a socket left behind by a previous run
+		if fi, err := os.Stat(path); err == nil && fi.Mode()&os.ModeSocket != 0 {
+			os.Remove(path)
+		}
+		return net.Listen("unix", path)
+	}
+
+	return net.Listen("tcp", addr)
+}
+
+// shutdownTimeout is the time given to the HTTP servers to finish the requests in progress when exiting.
+const shutdownTimeout = 2 * time.Second
+
+// shutdown gracefully shuts down the server, closing it if the requests in progress don't finish in time.
+func shutdown(server *http.Server) {
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+
+	if err := server.Shutdown(ctx); err != nil {
+		server.Close()
+	}
+}
+
+// browserURL returns the URL to open the address in a browser, using localhost for unspecified IPs (e.g. ':8080').
+func browserURL(addr net.Addr) string {
+	if host, port, err := net.SplitHostPort(addr.String()); err == nil {
+		if ip := net.ParseIP(host); ip != nil && ip.IsUnspecified() {
+			return "http://localhost:" + port
+		}
+	}
+
+	return "http://" + addr.String()
+}
@@ -1,7 +1,11 @@
 package cli
 
 import (
+	"fmt"
+	"net/http"
 	"os"
+	"regexp"
+	"strings"
 
 	"github.com/gustavooferreira/wcrawler/internal/graph"
 	"github.com/spf13/cobra"
@@ -12,13 +16,101 @@ func newViewCmd() *cobra.Command {
 		inputFilePath  string
 		outputFilePath string
 		noautoopen     bool
+		cdn            bool
+		serveaddr      string
+		hosts          []string
+		maxdepth       uint
+		statusclasses  []string
+		errorsonly     bool
+		urlregex       string
+		around         string
+		hops           uint
+		top            uint
+		topby          string
+		colorby        string
+		layout         string
+		format         string
+		labels         uint
+		aggregate      string
 	)
 
 	viewCmd := &cobra.Command{
 		Use:   "view",
 		Short: "View web links relationships in the browser",
-		Args:  cobra.NoArgs,
+		Long: "View web links relationships in the browser.\n" +
+			"The visualization library is inlined in the HTML file, so it works offline, unless --cdn is set.\n" +
+			"Builds without the library vendored (see 'make assets') need --cdn.\n" +
+			"For large crawls, use --serve to start a local server instead, from which the page loads the graph progressively.\n" +
+			"With --format svg or png, a static image of the graph is written instead of the HTML file.\n" +
+			"With --format tree, the pages are organised by URL path in collapsible lists instead.\n" +
+			"With --format json, the nodes and links are written as JSON, to export the graph to other tools.\n" +
+			"With --aggregate, pages are collapsed into one node per host or registered domain, " +
+			"linked by the number of links between them.",
+		Args: cobra.NoArgs,
 		RunE: func(cmd *cobra.Command, args []string) error {
+			if !containsString(graph.Formats, format) {
+				return fmt.Errorf("unsupported format %q (use %s)", format, strings.Join(graph.Formats, ", "))
+			}
+
+			if format != graph.FormatHTML {
+				if serveaddr != "" {
+					return fmt.Errorf("--serve only serves the HTML page, it can't be used with --format %s", format)
+				}
+				if !cmd.Flags().Changed("output") {
+					switch format {
+					case graph.FormatTree:
+						outputFilePath = strings.TrimSuffix(outputFilePath, ".html") + "_tree.html"
+					case graph.FormatJSON:
+						// Not to overwrite the crawl file
+						outputFilePath = strings.TrimSuffix(outputFilePath, ".html") + "_graph.json"
+					default:
+						outputFilePath = strings.TrimSuffix(outputFilePath, ".html") + "." + format
+					}
+				}
+			} else if !cdn && !graph.LibraryEmbedded() {
+				// Checked before loading the records, which can take a while
+				return graph.ErrLibraryMissing
+			}
+
+			if aggregate != "" {
+				if !containsString(graph.Aggregations, aggregate) {
+					return fmt.Errorf("unsupported aggregation %q (use %s)", aggregate, strings.Join(graph.Aggregations, ", "))
+				}
+				if format == graph.FormatTree {
+					return fmt.Errorf("--aggregate can't be used with --format tree, which organises the pages by URL path")
+				}
+			}
+
+			if errorsonly {
+				statusclasses = append(statusclasses, graph.ErrorStatusClasses...)
+			}
+
+			filter, err := newFilter(hosts, int(maxdepth), statusclasses, urlregex, around, int(hops), int(top), topby)
+			if err != nil {
+				return err
+			}
+
+			if !containsString(graph.ColorModes, colorby) {
+				return fmt.Errorf("unsupported colour mode %q (use %s)", colorby, strings.Join(graph.ColorModes, ", "))
+			}
+
+			if !containsString(graph.Layouts, layout) {
+				return fmt.Errorf("unsupported layout %q (use %s)", layout, strings.Join(graph.Layouts, ", "))
+			}
+
+			opts := graph.ViewerOptions{
+				UseCDN:    cdn,
+				Filter:    filter,
+				ColorBy:   colorby,
+				Layout:    layout,
+				Format:    format,
+				Labels:    int(labels),
+				Aggregate: aggregate,
+			}
+
+			if serveaddr != "" {
+				return serveGraph(cmd, inputFilePath, serveaddr, opts, !noautoopen)
+			}
 
 			iFile, err := os.Open(inputFilePath)
 			if err != nil {
@@ -33,10 +125,10 @@ func newViewCmd() *cobra.Command {
 
 			defer oFile.Close()
 
-			v := graph.NewViewer(iFile, oFile)
+			v := graph.NewViewer(iFile, oFile, opts)
 			err = v.Run()
 			if err != nil {
-				return nil
+				return err
 			}
 
 			if !noautoopen {
@@ -48,8 +140,103 @@ func newViewCmd() *cobra.Command {
 	}
 
 	viewCmd.Flags().StringVarP(&inputFilePath, "input", "i", "./web_graph.json", "file containing the data")
-	viewCmd.Flags().StringVarP(&outputFilePath, "output", "o", "./web_graph.html", "HTML output file")
+	viewCmd.Flags().StringVarP(&outputFilePath, "output", "o", "./web_graph.html", "output file (./web_graph.svg, .png, _tree.html or _graph.json with --format)")
+	viewCmd.Flags().StringVar(&format, "format", graph.FormatHTML, "output format: html (interactive page), svg or png (static image), "+
+		"tree (page with the pages organised by URL path, see the tree command) or json (nodes and links)")
+	viewCmd.Flags().UintVar(&labels, "labels", 10, "number of pages labelled with their URL in SVG images, the most linked to ones")
 	viewCmd.Flags().BoolVarP(&noautoopen, "noautoopen", "n", false, "don't open browser automatically")
+	viewCmd.Flags().StringVar(&serveaddr, "serve", "", "serve the graph on this address (e.g. ':8080') instead of writing an HTML file")
+	viewCmd.Flags().BoolVar(&cdn, "cdn", false, "load the visualization library from a CDN instead of inlining it in the HTML file")
+	viewCmd.Flags().StringVar(&colorby, "color-by", graph.ColorByDomain, "what to colour pages by when the graph is opened: "+
+		strings.Join(graph.ColorModes, ", "))
+	viewCmd.Flags().StringVar(&layout, "layout", graph.Layout3D, "how to lay out the graph: 3d or 2d (force-directed), "+
+		"tree (rows by crawl depth) or radial (rings by crawl depth around the seed)")
+	viewCmd.Flags().StringVar(&aggregate, "aggregate", "", "collapse the pages into one node per host or registered domain ('host' or 'domain'), "+
+		"with links weighted by the number of links between them (applied after the filters)")
+	viewCmd.Flags().StringSliceVar(&hosts, "host", nil, "only show pages on these hosts or their subdomains")
+	viewCmd.Flags().UintVar(&maxdepth, "max-depth", 0, "only show pages up to this depth (0 means no limit)")
+	viewCmd.Flags().StringSliceVar(&statusclasses, "status", nil, "only show pages with these status classes: 2xx, 3xx, 4xx, 5xx, error or unvisited")
+	viewCmd.Flags().BoolVar(&errorsonly, "errors-only", false, "only show pages that couldn't be fetched (same as --status 4xx,5xx,error)")
+	viewCmd.Flags().StringVar(&urlregex, "url-regex", "", "only show pages whose URL matches this regular expression")
+	viewCmd.Flags().StringVar(&around, "around", "", "only show pages up to --hops links away from this URL (following links both ways)")
+	viewCmd.Flags().UintVar(&hops, "hops", 1, "number of hops around the URL set with --around")
+	viewCmd.Flags().UintVar(&top, "top", 0, "only show the top N pages, ranked by --top-by")
+	viewCmd.Flags().StringVar(&topby, "top-by", graph.RankByDegree, "ranking used by --top: degree or pagerank")
 
 	return viewCmd
 }
+
+// serveGraph serves the graph of the records in the file until the process is interrupted.
+func serveGraph(cmd *cobra.Command, inputFilePath string, addr string, opts graph.ViewerOptions, openBrowser bool) error {
+	rm, err := loadRecords(inputFilePath)
+	if err != nil {
+		return err
+	}
+
+	server, err := graph.NewServer(rm, opts)
+	if err != nil {
+		return err
+	}
+
+	ln, err := listen(addr)
+	if err != nil {
+		return err
+	}
+
+	url := browserURL(ln.Addr())
+
+	fmt.Fprintf(cmd.OutOrStdout(), "Serving the graph of %d pages on %s (press Ctrl-C to stop)\n", rm.Count(), url)
+
+	if openBrowser {
+		graph.Openbrowser(url)
+	}
+
+	return http.Serve(ln, server)
+}
+
+// newFilter returns the filter of the pages to show, validating the flags.
+func newFilter(hosts []string, maxDepth int, statusClasses []string, urlRegex string, around string, hops int,
+	top int, topBy string) (graph.Filter, error) {
+
+	filter := graph.Filter{
+		Hosts:         hosts,
+		MaxDepth:      maxDepth,
+		StatusClasses: statusClasses,
+		EgoURL:        around,
+		EgoHops:       hops,
+		TopK:          top,
+		RankBy:        topBy,
+	}
+
+	for _, class := range statusClasses {
+		switch class {
+		case "2xx", "3xx", "4xx", "5xx", graph.StatusClassError, graph.StatusClassUnvisited:
+		default:
+			return graph.Filter{}, fmt.Errorf("unsupported status class %q (use 2xx, 3xx, 4xx, 5xx, error or unvisited)", class)
+		}
+	}
+
+	if topBy != graph.RankByDegree && topBy != graph.RankByPageRank {
+		return graph.Filter{}, fmt.Errorf("unsupported ranking %q (use 'degree' or 'pagerank')", topBy)
+	}
+
+	if urlRegex != "" {
+		pattern, err := regexp.Compile(urlRegex)
+		if err != nil {
+			return graph.Filter{}, fmt.Errorf("invalid URL regex: %w", err)
+		}
+		filter.URLPattern = pattern
+	}
+
+	return filter, nil
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,104 @@
+package wcrawler
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gustavooferreira/wcrawler/internal/httpjson"
+)
+
+// ControlStatus represents the settings of a running crawl, as returned by the ControlHandler.
+type ControlStatus struct {
+	Paused       bool     `json:"paused"`
+	Workers      int      `json:"workers"`
+	MaxDepth     int      `json:"maxDepth"`
+	BlockedHosts []string `json:"blockedHosts"`
+}
+
+// ControlHandler exposes a Controller over HTTP, so a running crawl can be steered without restarting it.
+//
+// Every endpoint replies with the ControlStatus as JSON:
+//
+//	GET  /status
+//	POST /pause
+//	POST /resume
+//	POST /workers?n=10
+//	POST /block-host?host=example.com
+//	POST /max-depth?depth=3
+type ControlHandler struct {
+	controller Controller
+	mux        *http.ServeMux
+}
+
+// NewControlHandler returns a new ControlHandler.
+func NewControlHandler(controller Controller) *ControlHandler {
+	h := ControlHandler{controller: controller, mux: http.NewServeMux()}
+
+	h.mux.HandleFunc("/status", h.handle(http.MethodGet, func(r *http.Request) error { return nil }))
+	h.mux.HandleFunc("/pause", h.handle(http.MethodPost, func(r *http.Request) error {
+		controller.Pause()
+		return nil
+	}))
+	h.mux.HandleFunc("/resume", h.handle(http.MethodPost, func(r *http.Request) error {
+		controller.Resume()
+		return nil
+	}))
+	h.mux.HandleFunc("/workers", h.handle(http.MethodPost, func(r *http.Request) error {
+		n, err := httpjson.IntParam(r, "n")
+		if err != nil {
+			return err
+		}
+		return controller.SetWorkers(n)
+	}))
+	h.mux.HandleFunc("/block-host", h.handle(http.MethodPost, func(r *http.Request) error {
+		host := r.FormValue("host")
+		if host == "" {
+			return fmt.Errorf("missing 'host' parameter")
+		}
+		controller.BlockHost(host)
+		return nil
+	}))
+	h.mux.HandleFunc("/max-depth", h.handle(http.MethodPost, func(r *http.Request) error {
+		depth, err := httpjson.IntParam(r, "depth")
+		if err != nil {
+			return err
+		}
+		return controller.SetMaxDepth(depth)
+	}))
+
+	return &h
+}
+
+// ServeHTTP dispatches the request to the endpoint for its path.
+func (h *ControlHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.mux.ServeHTTP(w, r)
+}
+
+// Status returns the current settings of the crawl.
+func (h *ControlHandler) Status() ControlStatus {
+	return ControlStatus{
+		Paused:       h.controller.Paused(),
+		Workers:      h.controller.Workers(),
+		MaxDepth:     h.controller.MaxDepth(),
+		BlockedHosts: h.controller.BlockedHosts(),
+	}
+}
+
+// handle returns a handler that only accepts the given method, runs the action and replies with the status.
+// Errors returned by the action are replied as bad requests.
+func (h *ControlHandler) handle(method string, action func(r *http.Request) error) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != method {
+			w.Header().Set("Allow", method)
+			httpjson.WriteError(w, http.StatusMethodNotAllowed, fmt.Errorf("method %s not allowed", r.Method))
+			return
+		}
+
+		if err := action(r); err != nil {
+			httpjson.WriteError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		httpjson.Write(w, http.StatusOK, h.Status())
+	}
+}
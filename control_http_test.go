@@ -0,0 +1,96 @@
+package wcrawler_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gustavooferreira/wcrawler"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestControlHandler(t *testing.T) {
+	tests := map[string]struct {
+		method             string
+		target             string
+		expectedStatusCode int
+		expectedStatus     wcrawler.ControlStatus
+	}{
+		"status": {
+			method:             http.MethodGet,
+			target:             "/status",
+			expectedStatusCode: http.StatusOK,
+			expectedStatus:     wcrawler.ControlStatus{Workers: 2, MaxDepth: 3},
+		},
+		"pause": {
+			method:             http.MethodPost,
+			target:             "/pause",
+			expectedStatusCode: http.StatusOK,
+			expectedStatus:     wcrawler.ControlStatus{Paused: true, Workers: 2, MaxDepth: 3},
+		},
+		"set workers": {
+			method:             http.MethodPost,
+			target:             "/workers?n=10",
+			expectedStatusCode: http.StatusOK,
+			expectedStatus:     wcrawler.ControlStatus{Workers: 10, MaxDepth: 3},
+		},
+		"invalid workers": {
+			method:             http.MethodPost,
+			target:             "/workers?n=0",
+			expectedStatusCode: http.StatusBadRequest,
+		},
+		"workers not a number": {
+			method:             http.MethodPost,
+			target:             "/workers?n=many",
+			expectedStatusCode: http.StatusBadRequest,
+		},
+		"block host": {
+			method:             http.MethodPost,
+			target:             "/block-host?host=example.com",
+			expectedStatusCode: http.StatusOK,
+			expectedStatus:     wcrawler.ControlStatus{Workers: 2, MaxDepth: 3, BlockedHosts: []string{"example.com"}},
+		},
+		"block host missing": {
+			method:             http.MethodPost,
+			target:             "/block-host",
+			expectedStatusCode: http.StatusBadRequest,
+		},
+		"set max depth": {
+			method:             http.MethodPost,
+			target:             "/max-depth?depth=0",
+			expectedStatusCode: http.StatusOK,
+			expectedStatus:     wcrawler.ControlStatus{Workers: 2, MaxDepth: 0},
+		},
+		"wrong method": {
+			method:             http.MethodGet,
+			target:             "/pause",
+			expectedStatusCode: http.StatusMethodNotAllowed,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			controller := &fakeController{workers: 2, maxDepth: 3}
+			handler := wcrawler.NewControlHandler(controller)
+
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, httptest.NewRequest(test.method, test.target, nil))
+
+			assert.Equal(t, test.expectedStatusCode, w.Code)
+			assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+
+			if test.expectedStatusCode != http.StatusOK {
+				var body struct{ Error string }
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+				assert.NotEmpty(t, body.Error)
+				return
+			}
+
+			var status wcrawler.ControlStatus
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &status))
+			assert.Equal(t, test.expectedStatus, status)
+		})
+	}
+}
@@ -0,0 +1,164 @@
+package wcrawler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// CrawlFileVersion is the version of the schema of the crawl files written by SaveToWriter (see crawl.schema.json).
+// Version 1 files, written before the schema was versioned, are a bare map of the records keyed by URL.
+const CrawlFileVersion = 2
+
+// Version is the version of wcrawler written in the header of the crawl files it explores.
+// Set at build time with -ldflags "-X github.com/gustavooferreira/wcrawler.Version=v1.2.3".
+var Version = "dev"
+
+// CrawlFile represents the file the records of a crawl are saved to.
+type CrawlFile struct {
+	SchemaVersion int         `json:"schemaVersion"`
+	Header        CrawlHeader `json:"header"`
+	// Records are sorted by index
+	Records []Record `json:"records"`
+}
+
+// CrawlHeader represents the metadata of a crawl. Fields are empty if unknown, e.g. for merged crawls
+// or files migrated from version 1.
+type CrawlHeader struct {
+	// ToolVersion is the version of wcrawler that crawled the records, kept when the file is saved again
+	ToolVersion string       `json:"toolVersion,omitempty"`
+	Config      *CrawlConfig `json:"config,omitempty"`
+	StartedAt   *time.Time   `json:"startedAt,omitempty"`
+	FinishedAt  *time.Time   `json:"finishedAt,omitempty"`
+	// StopReason is one of the StopReason strings (e.g. "Completed"), set once the crawl finished
+	StopReason string      `json:"stopReason,omitempty"`
+	Stats      *CrawlStats `json:"stats,omitempty"`
+	// MigratedFrom is the schema version of the file the records were first loaded from, if older
+	MigratedFrom int `json:"migratedFrom,omitempty"`
+}
+
+// CrawlConfig represents the settings a crawl was started with.
+type CrawlConfig struct {
+	SeedURL         string `json:"seedURL"`
+	Workers         int    `json:"workers"`
+	MaxDepth        int    `json:"maxDepth"`
+	Retry           int    `json:"retry"`
+	StayInSubdomain bool   `json:"stayInSubdomain"`
+	TreeMode        bool   `json:"treeMode"`
+	// Args are the command line arguments of the crawl, if set (see Crawler.SetArgs)
+	Args []string `json:"args,omitempty"`
+}
+
+// newCrawlFile returns the crawl file of the records and header in the RecordManager.
+func newCrawlFile(rm *RecordManager) CrawlFile {
+	file := CrawlFile{SchemaVersion: CrawlFileVersion, Header: rm.Header, Records: make([]Record, 0, len(rm.Records))}
+
+	for _, r := range rm.Records {
+		file.Records = append(file.Records, r)
+	}
+	sort.Slice(file.Records, func(i, j int) bool { return file.Records[i].Index < file.Records[j].Index })
+
+	return file
+}
+
+// parseCrawlFile parses a crawl file, migrating it from older schema versions, and validates its records.
+func parseCrawlFile(data []byte) (CrawlFile, error) {
+	// Version 1 files are a map keyed by URL, so they have no schemaVersion
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		var typeErr *json.UnmarshalTypeError
+		if errors.As(err, &typeErr) {
+			return CrawlFile{}, fmt.Errorf("invalid crawl file: expected a JSON object, got %s", typeErr.Value)
+		}
+		return CrawlFile{}, fmt.Errorf("invalid crawl file: %w", err)
+	}
+
+	var file CrawlFile
+	var err error
+
+	rawVersion, ok := fields["schemaVersion"]
+	if !ok {
+		file, err = migrateCrawlFileV1(data)
+	} else {
+		var version int
+		if err := json.Unmarshal(rawVersion, &version); err != nil {
+			return CrawlFile{}, fmt.Errorf("invalid crawl file: schemaVersion must be an integer")
+		}
+		if version != CrawlFileVersion {
+			return CrawlFile{}, fmt.Errorf("unsupported crawl file schema version %d "+
+				"(this version of wcrawler reads version %d, and version 1 files without a schemaVersion)", version, CrawlFileVersion)
+		}
+
+		err = json.Unmarshal(data, &file)
+	}
+	if err != nil {
+		return CrawlFile{}, fmt.Errorf("invalid crawl file: %w", err)
+	}
+
+	if err := validateRecords(file.Records); err != nil {
+		return CrawlFile{}, fmt.Errorf("invalid crawl file: %w", err)
+	}
+
+	return file, nil
+}
+
+// migrateCrawlFileV1 converts a version 1 file, a map of the records keyed by URL, to the current version.
+func migrateCrawlFileV1(data []byte) (CrawlFile, error) {
+	var records map[string]Record
+	if err := json.Unmarshal(data, &records); err != nil {
+		return CrawlFile{}, err
+	}
+
+	file := CrawlFile{SchemaVersion: CrawlFileVersion, Header: CrawlHeader{MigratedFrom: 1}}
+
+	for rawURL, r := range records {
+		if r.URL == "" {
+			r.URL = rawURL
+		}
+		if r.URL != rawURL {
+			return CrawlFile{}, fmt.Errorf("record %d is keyed by %q but has URL %q", r.Index, rawURL, r.URL)
+		}
+
+		file.Records = append(file.Records, r)
+	}
+	sort.Slice(file.Records, func(i, j int) bool { return file.Records[i].Index < file.Records[j].Index })
+
+	return file, nil
+}
+
+// validateRecords checks the records have a URL, a depth and an index, with no URL or index used twice,
+// and edges pointing to records in the file. Indexes don't have to be contiguous.
+func validateRecords(records []Record) error {
+	indexes := make(map[int]bool, len(records))
+	urls := make(map[string]bool, len(records))
+
+	for _, r := range records {
+		switch {
+		case r.URL == "":
+			return fmt.Errorf("record %d has no URL", r.Index)
+		case r.Index < 0:
+			return fmt.Errorf("record %q has a negative index (%d)", r.URL, r.Index)
+		case r.Depth < 0:
+			return fmt.Errorf("record %q has a negative depth (%d)", r.URL, r.Depth)
+		case indexes[r.Index]:
+			return fmt.Errorf("index %d is used by more than one record", r.Index)
+		case urls[r.URL]:
+			return fmt.Errorf("URL %q is used by more than one record", r.URL)
+		}
+
+		indexes[r.Index] = true
+		urls[r.URL] = true
+	}
+
+	for _, r := range records {
+		for edge := range r.Edges {
+			if !indexes[edge] {
+				return fmt.Errorf("record %q links to index %d, which isn't in the file", r.URL, edge)
+			}
+		}
+	}
+
+	return nil
+}
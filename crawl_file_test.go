@@ -0,0 +1,206 @@
+package wcrawler_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/gustavooferreira/wcrawler"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// schemaObject represents the parts of an object in crawl.schema.json checked against the Go types.
+type schemaObject struct {
+	Required   []string                   `json:"required"`
+	Properties map[string]json.RawMessage `json:"properties"`
+}
+
+func TestCrawlFileSchema(t *testing.T) {
+	data, err := os.ReadFile("crawl.schema.json")
+	require.NoError(t, err)
+
+	var schema struct {
+		schemaObject
+		Defs map[string]schemaObject `json:"$defs"`
+	}
+	require.NoError(t, json.Unmarshal(data, &schema))
+
+	var version struct {
+		Const int `json:"const"`
+	}
+	require.NoError(t, json.Unmarshal(schema.Properties["schemaVersion"], &version))
+	assert.Equal(t, wcrawler.CrawlFileVersion, version.Const)
+
+	// The properties of the objects are the JSON fields of the types, required unless they're omitted when empty
+	tests := map[string]struct {
+		object schemaObject
+		value  interface{}
+	}{
+		"file":    {object: schema.schemaObject, value: wcrawler.CrawlFile{}},
+		"header":  {object: schema.Defs["header"], value: wcrawler.CrawlHeader{}},
+		"config":  {object: schema.Defs["config"], value: wcrawler.CrawlConfig{}},
+		"stats":   {object: schema.Defs["stats"], value: wcrawler.CrawlStats{}},
+		"record":  {object: schema.Defs["record"], value: wcrawler.Record{}},
+		"timings": {object: schema.Defs["timings"], value: wcrawler.Timings{}},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var properties, required []string
+			typ := reflect.TypeOf(test.value)
+			for i := 0; i < typ.NumField(); i++ {
+				tag := strings.Split(typ.Field(i).Tag.Get("json"), ",")
+				properties = append(properties, tag[0])
+				if len(tag) == 1 {
+					required = append(required, tag[0])
+				}
+			}
+
+			schemaProperties := make([]string, 0, len(test.object.Properties))
+			for property := range test.object.Properties {
+				schemaProperties = append(schemaProperties, property)
+			}
+
+			sort.Strings(properties)
+			sort.Strings(required)
+			sort.Strings(schemaProperties)
+			sort.Strings(test.object.Required)
+			assert.Equal(t, properties, schemaProperties)
+			assert.Equal(t, required, test.object.Required)
+		})
+	}
+}
+
+func TestLoadFromReaderMigration(t *testing.T) {
+	// Version 1 files are a map keyed by URL, here with a gap in the indexes
+	input := `{"http://example.com/":{"index":0,"initPoint":true,"url":"http://example.com/",` +
+		`"host":"example.com","depth":0,"edges":[4],"statusCode":200},` +
+		`"http://example.com/about":{"index":4,"initPoint":false,` +
+		`"host":"example.com","depth":1,"edges":[],"statusCode":200}}`
+
+	rm := wcrawler.NewRecordManager()
+	require.NoError(t, rm.LoadFromReader(strings.NewReader(input)))
+
+	assert.Equal(t, 2, rm.Count())
+	assert.Equal(t, 1, rm.Header.MigratedFrom)
+
+	// The URL is taken from the key if missing
+	r, ok := rm.Get("http://example.com/about")
+	require.True(t, ok)
+	assert.Equal(t, "http://example.com/about", r.URL)
+
+	// New records go after the last index
+	assert.Equal(t, 5, rm.IndexCount)
+	rm.AddRecord(wcrawler.RMEntry{ParentURL: "http://example.com/about",
+		URL: wcrawler.URLEntity{NetLoc: "example.com", Raw: "http://example.com/contact"}, Depth: 2})
+	r, _ = rm.Get("http://example.com/contact")
+	assert.Equal(t, 5, r.Index)
+
+	// Saved in the current version
+	var buf bytes.Buffer
+	require.NoError(t, rm.SaveToWriter(&buf, false))
+
+	var file wcrawler.CrawlFile
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &file))
+	assert.Equal(t, wcrawler.CrawlFileVersion, file.SchemaVersion)
+	assert.Equal(t, 1, file.Header.MigratedFrom)
+	require.Len(t, file.Records, 3)
+	assert.Equal(t, []int{0, 4, 5}, []int{file.Records[0].Index, file.Records[1].Index, file.Records[2].Index})
+}
+
+func TestCrawlFileHeader(t *testing.T) {
+	var buf bytes.Buffer
+	c, err := wcrawler.NewCrawler(fakeConnector{pages: fakePages}, "http://example.com/", 1, &buf, nil, true, false, 2, 3)
+	require.NoError(t, err)
+
+	c.SetArgs([]string{"explore", "http://example.com/", "-d", "3"})
+	c.Run()
+
+	rm := wcrawler.NewRecordManager()
+	require.NoError(t, rm.LoadFromReader(&buf))
+
+	header := rm.Header
+	assert.Equal(t, "dev", header.ToolVersion)
+	assert.Equal(t, &wcrawler.CrawlConfig{SeedURL: "http://example.com/", Workers: 2, MaxDepth: 3, Retry: 1,
+		StayInSubdomain: true, Args: []string{"explore", "http://example.com/", "-d", "3"}}, header.Config)
+	require.NotNil(t, header.StartedAt)
+	require.NotNil(t, header.FinishedAt)
+	assert.False(t, header.FinishedAt.Before(*header.StartedAt))
+	assert.Equal(t, "Completed", header.StopReason)
+	require.NotNil(t, header.Stats)
+	assert.Equal(t, 3, header.Stats.PagesDiscovered)
+	assert.Equal(t, map[int]int{200: 2, 404: 1}, header.Stats.StatusCodes)
+	assert.Zero(t, header.MigratedFrom)
+}
+
+func TestSaveToWriterKeepsToolVersion(t *testing.T) {
+	input := `{"schemaVersion":2,"header":{"toolVersion":"v1.0.0","stopReason":"Completed"},` +
+		`"records":[{"index":0,"initPoint":true,"url":"http://example.com/","host":"example.com",` +
+		`"depth":0,"edges":[],"statusCode":200}]}`
+
+	rm := wcrawler.NewRecordManager()
+	require.NoError(t, rm.LoadFromReader(strings.NewReader(input)))
+
+	// e.g. 'analyze --writeback' saving the file again with another version of wcrawler
+	var buf bytes.Buffer
+	require.NoError(t, rm.SaveToWriter(&buf, false))
+
+	var file wcrawler.CrawlFile
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &file))
+	assert.Equal(t, "v1.0.0", file.Header.ToolVersion)
+	assert.Equal(t, "Completed", file.Header.StopReason)
+}
+
+func TestLoadFromReaderInvalid(t *testing.T) {
+	tests := map[string]struct {
+		input         string
+		expectedError string
+	}{
+		"not an object": {
+			input:         `[]`,
+			expectedError: "invalid crawl file: expected a JSON object, got array",
+		},
+		"newer version": {
+			input:         `{"schemaVersion":3,"header":{},"records":[]}`,
+			expectedError: "unsupported crawl file schema version 3",
+		},
+		"version not a number": {
+			input:         `{"schemaVersion":"2"}`,
+			expectedError: "invalid crawl file: schemaVersion must be an integer",
+		},
+		"no URL": {
+			input:         `{"schemaVersion":2,"header":{},"records":[{"index":0}]}`,
+			expectedError: "invalid crawl file: record 0 has no URL",
+		},
+		"duplicate index": {
+			input:         `{"schemaVersion":2,"header":{},"records":[{"index":1,"url":"http://a/"},{"index":1,"url":"http://b/"}]}`,
+			expectedError: "invalid crawl file: index 1 is used by more than one record",
+		},
+		"duplicate URL": {
+			input:         `{"schemaVersion":2,"header":{},"records":[{"index":0,"url":"http://a/"},{"index":1,"url":"http://a/"}]}`,
+			expectedError: `invalid crawl file: URL "http://a/" is used by more than one record`,
+		},
+		"unknown edge": {
+			input:         `{"schemaVersion":2,"header":{},"records":[{"index":0,"url":"http://a/","edges":[7]}]}`,
+			expectedError: `invalid crawl file: record "http://a/" links to index 7, which isn't in the file`,
+		},
+		"version 1 key mismatch": {
+			input:         `{"http://a/":{"index":0,"url":"http://b/"}}`,
+			expectedError: `invalid crawl file: record 0 is keyed by "http://a/" but has URL "http://b/"`,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			rm := wcrawler.NewRecordManager()
+			err := rm.LoadFromReader(strings.NewReader(test.input))
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), test.expectedError)
+		})
+	}
+}
@@ -1,41 +1,61 @@
 package wcrawler
 
 import (
+	"errors"
 	"fmt"
 	"io"
 	"sync"
 	"time"
 
-	"github.com/gosuri/uilive"
 	"github.com/oleiade/lane"
 )
 
 // Crawler brings everything together and is responsible for starting goroutines and manage them.
 type Crawler struct {
-	connector   Connector
-	statsWriter *uilive.Writer
+	connector    Connector
+	statsManager StatsManager
 
 	// Read-only vars
-	InitialURL      string
-	linksWriter     io.Writer
-	Stats           bool
-	ShowErrors      bool
-	WorkersCount    int
+	InitialURL  string
+	linksWriter io.Writer
+	// WorkersCount is the initial number of workers (see SetWorkers)
+	WorkersCount int
+	// Depth is the initial depth of recursion (see SetMaxDepth)
 	Depth           int
 	StayInSubdomain bool
 	TreeMode        bool
 	SubDomain       string
 	Retry           int
 
-	statsManager *StatsCLIOutWriter
-
 	// Channels
 	tasks   chan Task
 	results chan Result
+
+	// Control of the running crawl (see crawler_control.go)
+	control controlState
+
+	// Functions run by the Merger to look into the records and the queue (see crawler_inspect.go)
+	inspections chan inspection
+	mergerDone  chan struct{}
+
+	// graphListener gets the changes to the graph, if set (see crawler_graph.go)
+	graphListener GraphListener
+
+	// args are the command line arguments saved in the header of the output file, if set (see SetArgs)
+	args []string
+
+	// Outcome of the crawl, only available once Run returns.
+	// records is set under the control lock, as it can be inspected while Run is returning.
+	records    *RecordManager
+	startedAt  time.Time
+	finishedAt time.Time
+	stopReason StopReason
 }
 
 // NewCrawler returns a new Crawler.
-func NewCrawler(connector Connector, initialURL string, retry int, linksWriter io.Writer, stats bool, showErrors bool, stayinsubdomain bool, treemode bool, workersCount int, depth int) (*Crawler, error) {
+// statsManager receives the stats of the crawler while it runs. If nil, stats are discarded.
+// Use a MultiStatsManager to send stats to several places.
+func NewCrawler(connector Connector, initialURL string, retry int, linksWriter io.Writer, statsManager StatsManager, stayinsubdomain bool, treemode bool, workersCount int, depth int) (*Crawler, error) {
 
 	urlEntity, err := ExtractURL(initialURL)
 	if err != nil {
@@ -50,19 +70,28 @@ func NewCrawler(connector Connector, initialURL string, retry int, linksWriter i
 		return nil, fmt.Errorf("recursion depth needs to be greater or equal to 0")
 	}
 
-	return &Crawler{
-			connector:       connector,
-			InitialURL:      urlEntity.Raw,
-			linksWriter:     linksWriter,
-			Stats:           stats,
-			ShowErrors:      showErrors,
-			WorkersCount:    workersCount,
-			Depth:           depth,
-			StayInSubdomain: stayinsubdomain,
-			TreeMode:        treemode,
-			SubDomain:       urlEntity.NetLoc,
-			Retry:           retry},
-		nil
+	if statsManager == nil {
+		statsManager = NoopStatsManager{}
+	}
+
+	c := &Crawler{
+		connector:       connector,
+		statsManager:    statsManager,
+		InitialURL:      urlEntity.Raw,
+		linksWriter:     linksWriter,
+		WorkersCount:    workersCount,
+		Depth:           depth,
+		StayInSubdomain: stayinsubdomain,
+		TreeMode:        treemode,
+		SubDomain:       urlEntity.NetLoc,
+		Retry:           retry,
+		inspections:     make(chan inspection),
+		mergerDone:      make(chan struct{}),
+	}
+	c.control.workersTarget = workersCount
+	c.control.maxDepth = depth
+
+	return c, nil
 }
 
 // Run starts crawling.
@@ -74,64 +103,112 @@ func (c *Crawler) Run() {
 
 	var wg sync.WaitGroup
 
-	// Start stats goroutine
-	if c.Stats {
-
-		c.statsWriter = uilive.New()
-
-		sm := NewStatsCLIOutWriter(c.statsWriter, c.ShowErrors, c.WorkersCount, c.Depth)
-		wg.Add(1)
-		c.statsManager = sm
-		go c.StatsWriter(&wg)
+	c.startedAt = time.Now()
 
-		c.statsManager.SetAppState(AppState_Running)
+	// Start stats goroutine
+	c.statsManager.SetAppState(AppState_Running)
+	if c.Paused() {
+		c.statsManager.SetAppState(AppState_Paused)
 	}
+	wg.Add(1)
+	go c.StatsWriter(&wg)
 
 	// Start merger goroutine (deals with records manager)
 	wg.Add(1)
 	go c.Merger(&wg)
 
-	// Start workers (n workers)
-	for i := 0; i < int(c.WorkersCount); i++ {
-		wg.Add(1)
-		go c.WorkerRun(&wg)
-	}
+	// Start workers (n workers, which can be changed with SetWorkers)
+	c.startWorkers(&wg)
 
 	// Start goroutine that handles Ctrl-C (which will close all channels and drain them as well)
 	// upon receiving sigInt, inform Merger to stop processing any more links.
 
 	// wait for all goroutines to complete
 	wg.Wait()
+
+	c.finishedAt = time.Now()
+}
+
+// Summary returns the end-of-crawl report listing the top pages by time, size and depth.
+// It must only be called after Run returns.
+func (c *Crawler) Summary(top int) CrawlSummary {
+	rm := c.records
+	if rm == nil {
+		rm = NewRecordManager()
+	}
+
+	return NewCrawlSummary(rm, c.startedAt, c.finishedAt, c.stopReason, top)
+}
+
+// SetArgs sets the command line arguments saved in the header of the output file, along with the crawl settings.
+// It must be called before Run.
+func (c *Crawler) SetArgs(args []string) {
+	c.args = args
+}
+
+// newCrawlHeader returns the header of the output file of a crawl starting now.
+func (c *Crawler) newCrawlHeader() CrawlHeader {
+	startedAt := c.startedAt
+
+	return CrawlHeader{
+		ToolVersion: Version,
+		Config: &CrawlConfig{
+			SeedURL:         c.InitialURL,
+			Workers:         c.WorkersCount,
+			MaxDepth:        c.Depth,
+			Retry:           c.Retry,
+			StayInSubdomain: c.StayInSubdomain,
+			TreeMode:        c.TreeMode,
+			Args:            c.args,
+		},
+		StartedAt: &startedAt,
+	}
 }
 
 // WorkerRun represents the workers crawling links in a goroutine.
 // Receives tasks in a channel and returns results on another.
-// When tasks channel is closed, the workers return.
+// When tasks channel is closed, or there are more workers than needed, the workers return.
 func (c *Crawler) WorkerRun(wg *sync.WaitGroup) {
 	defer wg.Done()
 
 	for t := range c.tasks {
-		if c.Stats {
-			c.statsManager.IncDecWorkersRunning(1)
+		c.waitIfPaused()
+
+		if c.stopped() || c.hostBlocked(t.Host) {
+			c.results <- Result{ParentURL: t.URL, Depth: t.Depth, Skipped: true}
+			if c.retireWorker() {
+				return
+			}
+			continue
 		}
 
+		c.statsManager.IncDecWorkersRunning(1)
+		c.statsManager.IncDecHostWorkersRunning(t.Host, 1)
+
 		var statusCode int
 		var links []URLEntity
-		var latency time.Duration
+		var info ResponseInfo
 		var err error
 
 		// retry
 		for i := 0; i <= c.Retry; i++ {
-			statusCode, links, latency, err = c.connector.GetLinks(t.URL)
+			statusCode, links, info, err = c.connector.GetLinks(t.URL)
 			if err == nil {
 				// Only retry if timeout!
 				break
 			}
 		}
 
+		// Classify errors here, so it's done the same way whatever the Connector
+		var fetchErr *FetchError
+		if err != nil && !errors.As(err, &fetchErr) {
+			err = &FetchError{Category: ClassifyError(statusCode, err), Err: err}
+		}
+
 		r := Result{
 			ParentURL:  t.URL,
 			StatusCode: statusCode,
+			Info:       info,
 			Links:      links,
 			Depth:      t.Depth,
 			Err:        err,
@@ -139,12 +216,21 @@ func (c *Crawler) WorkerRun(wg *sync.WaitGroup) {
 
 		c.results <- r
 
-		if c.Stats {
-			c.statsManager.IncDecWorkersRunning(-1)
-			c.statsManager.IncDecTotalRequestsCount(1)
-			c.statsManager.AddLatencySample(latency)
+		c.statsManager.IncDecWorkersRunning(-1)
+		c.statsManager.IncDecHostWorkersRunning(t.Host, -1)
+		c.statsManager.IncDecTotalRequestsCount(1)
+		// Failed requests (e.g. timeouts) got no response, so they have no latency to sample
+		if err == nil && info.Timings.TTFB > 0 {
+			c.statsManager.AddLatencySample(info.Timings.TTFB)
+		}
+		c.statsManager.AddResponseSample(t.Host, statusCode, info, err)
+
+		if c.retireWorker() {
+			return
 		}
 	}
+
+	c.workerDone()
 }
 
 // Merger gets the results from the workers (links) and keeps all the relevant information
@@ -158,39 +244,60 @@ func (c *Crawler) Merger(wg *sync.WaitGroup) {
 	jobsCounter := 0
 	var err error
 
+	// Keep the links left unvisited because of the max depth, in case it's raised (see SetMaxDepth)
+	var deferred []Task
+
 	// Create queue for queuing jobs
 	queue := lane.NewQueue()
 
 	// Initialize record manager
 	rm := NewRecordManager()
+	rm.Header = c.newCrawlHeader()
 
 	// Add baseURL to tasks channel
-	task := Task{URL: c.InitialURL, Depth: 0}
+	task := Task{URL: c.InitialURL, Host: c.SubDomain, Depth: 0}
 	c.tasks <- task
 
 	// Add baseURL as an entry to Record Manager
 	re := RMEntry{ParentURL: "", URL: URLEntity{NetLoc: c.SubDomain, Raw: c.InitialURL}, Depth: 0}
 	rm.AddRecord(re)
+	c.notifyRecord(rm, c.InitialURL)
 
 	jobsCounter++
 
-	if c.Stats {
-		c.statsManager.SetLinksInQueue(jobsCounter)
-	}
+	c.statsManager.SetLinksInQueue(jobsCounter)
 
 	// ---------
 
 	for {
-		r := <-c.results
+		var r Result
+		select {
+		case r = <-c.results:
+		case inspect := <-c.inspections:
+			inspect(rm, queue, len(deferred))
+			continue
+		}
+
 		// Got a response means we can decrement the job counter
 		jobsCounter--
 
+		if r.Skipped {
+			c.dispatch(queue, &deferred, &jobsCounter)
+			if jobsCounter == 0 {
+				close(c.tasks)
+				break
+			}
+			continue
+		}
+
 		// Update parent URL entry in Record Manager
 		err = rm.Update(r.ParentURL, r.StatusCode, r.Err)
 		if err != nil {
 			// log
 			// continue
 		}
+		rm.SetResponseInfo(r.ParentURL, r.Info)
+		c.notifyRecord(rm, r.ParentURL)
 
 		// when processing the new links, make sure every time we queue a new link
 		// we increase the jobCounter
@@ -208,56 +315,60 @@ func (c *Crawler) Merger(wg *sync.WaitGroup) {
 				if !rm.Exists(uu.Raw) {
 					rme := RMEntry{ParentURL: r.ParentURL, URL: uu, Depth: r.Depth + 1}
 					rm.AddRecord(rme)
+					c.notifyRecord(rm, uu.Raw)
+					c.notifyLink(rm, r.ParentURL, uu.Raw)
 
 					// This means we will have entries in the cache that weren't tested
 					// i.e., we didn't make a request, therefore statuscode will be 0.
 					// We can use this as an indication as to whether a request has been made,
 					// to a given URL or not.
-					if r.Depth < c.Depth || c.Depth == 0 {
-						queue.Enqueue(Task{URL: uu.Raw, Depth: r.Depth + 1})
+					task := Task{URL: uu.Raw, Host: uu.NetLoc, Depth: r.Depth + 1}
+					if c.withinMaxDepth(task.Depth) {
+						queue.Enqueue(task)
 						jobsCounter++
+					} else {
+						deferred = append(deferred, task)
 					}
 				} else {
 					if !c.TreeMode {
 						rm.AddEdge(r.ParentURL, uu.Raw)
+						rm.AddAnchorText(r.ParentURL, uu.Raw, uu.AnchorText)
+						c.notifyLink(rm, r.ParentURL, uu.Raw)
 					}
 				}
 			}
 		}
 
-		if c.Stats {
-			if r.Err != nil {
-				c.statsManager.IncDecErrorsCount(1)
-				c.statsManager.AddErrorEntry(r.Err.Error())
-			} else if r.StatusCode < 200 || r.StatusCode >= 300 {
-				c.statsManager.IncDecErrorsCount(1)
-				c.statsManager.AddErrorEntry(fmt.Sprintf("error: status code received: %d", r.StatusCode))
-			}
-
-			c.statsManager.SetLinksInQueue(jobsCounter)
-			c.statsManager.SetLinksCount(rm.Count())
-			c.statsManager.SetDepth(r.Depth)
+		category := ClassifyError(r.StatusCode, r.Err)
+		event := FetchEvent{
+			Time:          time.Now(),
+			URL:           r.ParentURL,
+			Depth:         r.Depth,
+			StatusCode:    r.StatusCode,
+			ErrorCategory: category,
+			Size:          r.Info.BytesRead,
+			Duration:      r.Info.Timings.Total,
+		}
+		if record, ok := rm.Get(r.ParentURL); ok {
+			event.Host = record.Host
+		}
+		if r.Err != nil {
+			event.Error = r.Err.Error()
+		}
+		c.statsManager.AddFetchEvent(event)
+
+		if r.Err != nil {
+			c.statsManager.IncDecErrorsCount(1)
+			c.statsManager.AddErrorEntry(fmt.Sprintf("[%s] %s", category, r.Err.Error()))
+		} else if category != ErrorCategory_None {
+			c.statsManager.IncDecErrorsCount(1)
+			c.statsManager.AddErrorEntry(fmt.Sprintf("[%s] error: status code received: %d", category, r.StatusCode))
 		}
 
-		// fill tasks channel until either channel blocks or queue is empty
-		for {
-			// Check if channel is full
-			// This is fine because this goroutine is the only one writing to the channel,
-			// so it won't block when we actually try to write to the channel.
-			// If it says the channel is full and the very next millisecond it's not,
-			// there is no problem as we will come back to this to refill it.
-			if len(c.tasks) == cap(c.tasks) {
-				break
-			}
+		c.statsManager.SetLinksCount(rm.Count())
+		c.statsManager.SetDepth(r.Depth)
 
-			// Check if we can dequeue an item from the queue, if yes, try to push it to the channel
-			if queue.Empty() {
-				// No more items to dequeue
-				break
-			} else {
-				c.tasks <- queue.Dequeue().(Task)
-			}
-		}
+		c.dispatch(queue, &deferred, &jobsCounter)
 
 		// check if we are done (i.e., no more jobs)
 		if jobsCounter == 0 {
@@ -266,22 +377,99 @@ func (c *Crawler) Merger(wg *sync.WaitGroup) {
 		}
 	}
 
+	c.control.mu.Lock()
+	c.control.finished = true
+	c.records = rm
+	c.stopReason = StopReason_Completed
+	if c.control.stopped {
+		c.stopReason = StopReason_Stopped
+	} else if len(deferred) != 0 {
+		c.stopReason = StopReason_MaxDepth
+	}
+	finishedAt := time.Now()
+	stats := NewCrawlStats(rm)
+	rm.Header.FinishedAt = &finishedAt
+	rm.Header.StopReason = c.stopReason.String()
+	rm.Header.Stats = &stats
+	c.control.mu.Unlock()
+	close(c.mergerDone)
+
+	if c.graphListener != nil {
+		c.graphListener.Finished()
+	}
+
 	// Write to file
 	err = rm.SaveToWriter(c.linksWriter, true)
 	if err != nil {
 		// log
 	}
 
-	if c.Stats {
-		c.statsManager.SetAppState(AppState_Finished)
+	c.statsManager.SetAppState(AppState_Finished)
+}
+
+// dispatch fills the tasks channel until either the channel is full or the queue is empty.
+// Tasks for blocked hosts are dropped and tasks beyond the max depth are deferred.
+// Deferred tasks are queued again when the max depth is raised.
+func (c *Crawler) dispatch(queue *lane.Queue, deferred *[]Task, jobsCounter *int) {
+	// Once stopped, the links in the queue are dropped and only the requests in flight are waited for
+	if c.stopped() {
+		for !queue.Empty() {
+			queue.Dequeue()
+			*jobsCounter--
+		}
+		c.statsManager.SetLinksInQueue(*jobsCounter)
+		return
 	}
+
+	if c.maxDepthRaised() {
+		var stillDeferred []Task
+		for _, t := range *deferred {
+			if c.withinMaxDepth(t.Depth) {
+				queue.Enqueue(t)
+				*jobsCounter++
+			} else {
+				stillDeferred = append(stillDeferred, t)
+			}
+		}
+		*deferred = stillDeferred
+	}
+
+	for {
+		// Check if channel is full
+		// This is fine because this goroutine is the only one writing to the channel,
+		// so it won't block when we actually try to write to the channel.
+		// If it says the channel is full and the very next millisecond it's not,
+		// there is no problem as we will come back to this to refill it.
+		if len(c.tasks) == cap(c.tasks) {
+			break
+		}
+
+		// Check if we can dequeue an item from the queue, if yes, try to push it to the channel
+		if queue.Empty() {
+			// No more items to dequeue
+			break
+		}
+
+		t := queue.Dequeue().(Task)
+		if c.hostBlocked(t.Host) {
+			*jobsCounter--
+			continue
+		}
+
+		if !c.withinMaxDepth(t.Depth) {
+			*deferred = append(*deferred, t)
+			*jobsCounter--
+			continue
+		}
+
+		c.tasks <- t
+	}
+
+	c.statsManager.SetLinksInQueue(*jobsCounter)
 }
 
-// StatsWriter writes stats to a io.Writer (e.g. os.Stdout)
+// StatsWriter runs the StatsManager's output flusher until the crawler finishes.
 func (c *Crawler) StatsWriter(wg *sync.WaitGroup) {
 	defer wg.Done()
-
-	c.statsWriter.Start()
-	defer c.statsWriter.Stop() // flush and stop rendering
 	c.statsManager.RunOutputFlusher()
 }
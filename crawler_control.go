@@ -0,0 +1,251 @@
+package wcrawler
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// controlState keeps track of the changes made to a running crawl.
+type controlState struct {
+	mu sync.Mutex
+	// resumed is signaled when the crawler resumes
+	resumed *sync.Cond
+
+	paused bool
+	// blocked hosts don't get any more requests
+	blockedHosts map[string]bool
+
+	// workers target and number of workers running.
+	// While there are more workers active than the target, workers return after their current task.
+	workersTarget int
+	workersActive int
+	wg            *sync.WaitGroup
+	// max depth of recursion (0 means no limit)
+	maxDepth int
+	// maxDepthRaised is set when the max depth is raised, so the Merger queues the deferred links
+	maxDepthRaised bool
+
+	// stopped is set when the crawl is stopped, so no more requests are made
+	stopped bool
+
+	// finished is set once the Merger closes the tasks channel (no workers can be started anymore)
+	finished bool
+}
+
+// Pause stops requests from being made. Requests in flight still complete.
+func (c *Crawler) Pause() {
+	c.control.mu.Lock()
+	defer c.control.mu.Unlock()
+
+	if c.control.paused || c.control.finished {
+		return
+	}
+
+	c.control.paused = true
+	c.statsManager.SetAppState(AppState_Paused)
+}
+
+// Resume resumes making requests.
+func (c *Crawler) Resume() {
+	c.control.mu.Lock()
+	defer c.control.mu.Unlock()
+
+	if !c.control.paused {
+		return
+	}
+
+	c.control.paused = false
+	if !c.control.finished {
+		c.statsManager.SetAppState(AppState_Running)
+	}
+
+	if c.control.resumed != nil {
+		c.control.resumed.Broadcast()
+	}
+}
+
+// Stop stops the crawl: links waiting in the queue aren't requested anymore, requests in flight still complete.
+// Run returns once they do, saving the records found so far.
+func (c *Crawler) Stop() {
+	c.control.mu.Lock()
+	c.control.stopped = true
+	c.control.mu.Unlock()
+
+	// Paused workers need to get going to drop their tasks
+	c.Resume()
+}
+
+// Paused returns true if the crawler is paused.
+func (c *Crawler) Paused() bool {
+	c.control.mu.Lock()
+	defer c.control.mu.Unlock()
+	return c.control.paused
+}
+
+// SetWorkers changes the number of workers making concurrent requests.
+// When decreasing, workers return once they finish their current request.
+func (c *Crawler) SetWorkers(n int) error {
+	if n <= 0 {
+		return fmt.Errorf("the number of workers needs to be greater than 0")
+	}
+
+	c.control.mu.Lock()
+	defer c.control.mu.Unlock()
+
+	c.control.workersTarget = n
+	c.statsManager.SetTotalWorkersCount(n)
+
+	// Not running yet, workers are started by Run
+	if c.control.wg == nil {
+		return nil
+	}
+
+	for c.control.workersActive < c.control.workersTarget && !c.control.finished {
+		c.startWorker()
+	}
+
+	return nil
+}
+
+// Workers returns the number of workers making concurrent requests.
+func (c *Crawler) Workers() int {
+	c.control.mu.Lock()
+	defer c.control.mu.Unlock()
+	return c.control.workersTarget
+}
+
+// BlockHost stops requests to a host (e.g. a misbehaving one).
+// Links to pages on the host are still recorded, but the pages aren't requested.
+func (c *Crawler) BlockHost(host string) {
+	c.control.mu.Lock()
+	defer c.control.mu.Unlock()
+
+	if c.control.blockedHosts == nil {
+		c.control.blockedHosts = map[string]bool{}
+	}
+	c.control.blockedHosts[host] = true
+}
+
+// BlockedHosts returns the hosts blocked, sorted alphabetically.
+func (c *Crawler) BlockedHosts() []string {
+	c.control.mu.Lock()
+	defer c.control.mu.Unlock()
+
+	hosts := make([]string, 0, len(c.control.blockedHosts))
+	for host := range c.control.blockedHosts {
+		hosts = append(hosts, host)
+	}
+	sort.Strings(hosts)
+
+	return hosts
+}
+
+// SetMaxDepth changes the depth of recursion (0 means no limit).
+// Lowering it stops links beyond the new depth from being requested, raising it
+// (while the crawler is running) queues the links left unvisited because of the previous depth.
+func (c *Crawler) SetMaxDepth(depth int) error {
+	if depth < 0 {
+		return fmt.Errorf("recursion depth needs to be greater or equal to 0")
+	}
+
+	c.control.mu.Lock()
+	defer c.control.mu.Unlock()
+
+	if depth == 0 || (c.control.maxDepth != 0 && depth > c.control.maxDepth) {
+		c.control.maxDepthRaised = true
+	}
+
+	c.control.maxDepth = depth
+	c.statsManager.SetMaxDepthLevel(depth)
+
+	return nil
+}
+
+// MaxDepth returns the depth of recursion (0 means no limit).
+func (c *Crawler) MaxDepth() int {
+	c.control.mu.Lock()
+	defer c.control.mu.Unlock()
+	return c.control.maxDepth
+}
+
+// startWorkers starts the initial workers.
+func (c *Crawler) startWorkers(wg *sync.WaitGroup) {
+	c.control.mu.Lock()
+	defer c.control.mu.Unlock()
+
+	c.control.wg = wg
+	c.control.resumed = sync.NewCond(&c.control.mu)
+
+	for i := 0; i < c.control.workersTarget; i++ {
+		c.startWorker()
+	}
+}
+
+// startWorker starts a new worker. Lock must be held by the caller.
+func (c *Crawler) startWorker() {
+	c.control.workersActive++
+	c.control.wg.Add(1)
+	go c.WorkerRun(c.control.wg)
+}
+
+// retireWorker returns true if the worker calling it must return, as there are more workers than needed.
+func (c *Crawler) retireWorker() bool {
+	c.control.mu.Lock()
+	defer c.control.mu.Unlock()
+
+	if c.control.workersActive > c.control.workersTarget {
+		c.control.workersActive--
+		return true
+	}
+
+	return false
+}
+
+// workerDone keeps track of a worker returning because there are no more tasks.
+func (c *Crawler) workerDone() {
+	c.control.mu.Lock()
+	defer c.control.mu.Unlock()
+	c.control.workersActive--
+}
+
+// waitIfPaused blocks while the crawler is paused.
+func (c *Crawler) waitIfPaused() {
+	c.control.mu.Lock()
+	defer c.control.mu.Unlock()
+
+	for c.control.paused {
+		c.control.resumed.Wait()
+	}
+}
+
+// stopped returns true if the crawl was stopped.
+func (c *Crawler) stopped() bool {
+	c.control.mu.Lock()
+	defer c.control.mu.Unlock()
+	return c.control.stopped
+}
+
+// hostBlocked returns true if requests to the host are blocked.
+func (c *Crawler) hostBlocked(host string) bool {
+	c.control.mu.Lock()
+	defer c.control.mu.Unlock()
+	return c.control.blockedHosts[host]
+}
+
+// withinMaxDepth returns true if pages at the given depth can be requested.
+func (c *Crawler) withinMaxDepth(depth int) bool {
+	c.control.mu.Lock()
+	defer c.control.mu.Unlock()
+	return c.control.maxDepth == 0 || depth <= c.control.maxDepth
+}
+
+// maxDepthRaised returns true if the max depth was raised since the last time it was called.
+func (c *Crawler) maxDepthRaised() bool {
+	c.control.mu.Lock()
+	defer c.control.mu.Unlock()
+
+	raised := c.control.maxDepthRaised
+	c.control.maxDepthRaised = false
+	return raised
+}
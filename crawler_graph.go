@@ -0,0 +1,36 @@
+package wcrawler
+
+// SetGraphListener sets the GraphListener told about the changes to the graph of pages.
+// It needs to be set before calling Run.
+func (c *Crawler) SetGraphListener(listener GraphListener) {
+	c.graphListener = listener
+}
+
+// notifyRecord tells the GraphListener, if any, about the record of the URL.
+func (c *Crawler) notifyRecord(rm *RecordManager, rawURL string) {
+	if c.graphListener == nil {
+		return
+	}
+
+	if r, ok := rm.Get(rawURL); ok {
+		c.graphListener.UpdateRecord(r)
+	}
+}
+
+// notifyLink tells the GraphListener, if any, about the link between the URLs.
+func (c *Crawler) notifyLink(rm *RecordManager, fromURL string, toURL string) {
+	if c.graphListener == nil {
+		return
+	}
+
+	from, ok := rm.Get(fromURL)
+	if !ok {
+		return
+	}
+	to, ok := rm.Get(toURL)
+	if !ok {
+		return
+	}
+
+	c.graphListener.AddLink(from.Index, to.Index)
+}
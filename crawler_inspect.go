@@ -0,0 +1,92 @@
+package wcrawler
+
+import (
+	"bytes"
+	"io"
+
+	"github.com/oleiade/lane"
+)
+
+// Frontier represents the links waiting to be requested.
+type Frontier struct {
+	// Size is the number of links waiting to be requested
+	Size int `json:"size"`
+	// Deferred is the number of links left unvisited because of the max depth (see SetMaxDepth)
+	Deferred int `json:"deferred"`
+	// Sample lists the next links to be requested, after the ones already handed to the workers
+	Sample []Task `json:"sample"`
+}
+
+// inspection is run by the Merger, which owns the records and the queue of links.
+type inspection func(rm *RecordManager, queue *lane.Queue, deferred int)
+
+// Frontier returns the links waiting to be requested, with a sample of up to n links.
+func (c *Crawler) Frontier(n int) Frontier {
+	frontier := Frontier{Sample: []Task{}}
+
+	c.inspect(func(rm *RecordManager, queue *lane.Queue, deferred int) {
+		size := queue.Size()
+		frontier.Size = size + len(c.tasks)
+		frontier.Deferred = deferred
+
+		// The queue can't be iterated, so it's rotated to keep its order
+		for i := 0; i < size; i++ {
+			t := queue.Dequeue()
+			if i < n {
+				frontier.Sample = append(frontier.Sample, t.(Task))
+			}
+			queue.Enqueue(t)
+		}
+	})
+
+	return frontier
+}
+
+// WriteRecords writes a snapshot of the records collected so far in JSON format
+// (the same format as the output file).
+func (c *Crawler) WriteRecords(w io.Writer) error {
+	var buf bytes.Buffer
+	var err error
+
+	// Encode while the Merger waits, but write afterwards so a slow writer doesn't hold the crawl
+	c.inspect(func(rm *RecordManager, queue *lane.Queue, deferred int) {
+		err = rm.SaveToWriter(&buf, false)
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = buf.WriteTo(w)
+	return err
+}
+
+// inspect runs f on the Merger's goroutine, so f can safely look into the records and the queue.
+// If the crawler isn't running, f gets the records of the finished crawl (or no records if it didn't start).
+func (c *Crawler) inspect(f inspection) {
+	c.control.mu.Lock()
+	running := c.control.wg != nil && !c.control.finished
+	c.control.mu.Unlock()
+
+	if running {
+		done := make(chan struct{})
+		select {
+		case c.inspections <- func(rm *RecordManager, queue *lane.Queue, deferred int) {
+			f(rm, queue, deferred)
+			close(done)
+		}:
+			<-done
+			return
+		case <-c.mergerDone:
+		}
+	}
+
+	c.control.mu.Lock()
+	rm := c.records
+	c.control.mu.Unlock()
+
+	if rm == nil {
+		rm = NewRecordManager()
+	}
+
+	f(rm, lane.NewQueue(), 0)
+}
@@ -0,0 +1,435 @@
+package wcrawler_test
+
+import (
+	"bytes"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gustavooferreira/wcrawler"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeConnector serves pages from a map of URL to links.
+type fakeConnector struct {
+	pages map[string][]string
+}
+
+func (fc fakeConnector) GetLinks(rawURL string) (int, []wcrawler.URLEntity, wcrawler.ResponseInfo, error) {
+	links, ok := fc.pages[rawURL]
+	if !ok {
+		return 404, nil, wcrawler.ResponseInfo{}, nil
+	}
+
+	entities := []wcrawler.URLEntity{}
+	for _, l := range links {
+		urlEntity, err := wcrawler.ExtractURL(l)
+		if err != nil {
+			return 0, nil, wcrawler.ResponseInfo{}, err
+		}
+		entities = append(entities, urlEntity)
+	}
+
+	return 200, entities, wcrawler.ResponseInfo{BytesRead: 10}, nil
+}
+
+var fakePages = map[string][]string{
+	"http://example.com/":      {"http://example.com/about", "http://example.com/missing"},
+	"http://example.com/about": {"http://example.com/"},
+}
+
+func TestCrawlerRun(t *testing.T) {
+	tests := map[string]struct {
+		statsManager wcrawler.StatsManager
+	}{
+		"no stats manager": {
+			statsManager: nil,
+		},
+		"json stats writer": {
+			statsManager: wcrawler.NewStatsJSONWriter(&bytes.Buffer{}, 2, 3),
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var buf bytes.Buffer
+
+			c, err := wcrawler.NewCrawler(fakeConnector{pages: fakePages}, "http://example.com/", 0, &buf,
+				test.statsManager, false, false, 2, 3)
+			require.NoError(t, err)
+
+			c.Run()
+
+			rm := wcrawler.NewRecordManager()
+			err = rm.LoadFromReader(&buf)
+			require.NoError(t, err)
+
+			assert.Equal(t, 3, rm.Count())
+
+			r, ok := rm.Get("http://example.com/missing")
+			require.True(t, ok)
+			assert.Equal(t, 404, r.StatusCode)
+		})
+	}
+}
+
+func TestCrawlerStatsManager(t *testing.T) {
+	var statsBuf bytes.Buffer
+	var cliBuf bytes.Buffer
+
+	jsonWriter := wcrawler.NewStatsJSONWriter(&statsBuf, 2, 3)
+	cliWriter := wcrawler.NewStatsCLIOutWriter(&cliBuf, true, 2, 3)
+
+	c, err := wcrawler.NewCrawler(fakeConnector{pages: fakePages}, "http://example.com/", 0, &bytes.Buffer{},
+		wcrawler.NewMultiStatsManager(jsonWriter, cliWriter), false, false, 2, 3)
+	require.NoError(t, err)
+
+	c.Run()
+
+	snapshot := jsonWriter.Snapshot()
+	assert.Equal(t, "Finished", snapshot.State)
+	assert.Equal(t, 3, snapshot.TotalRequestsCount)
+	assert.Equal(t, 3, snapshot.LinksCount)
+	assert.Equal(t, 1, snapshot.ErrorsCount)
+	assert.Equal(t, []string{fmt.Sprintf("[http_4xx] error: status code received: %d", 404)}, snapshot.LastErrors)
+	assert.Contains(t, statsBuf.String(), `"totalRequestsCount": 3`)
+
+	// Each sink keeps its own clock, so rates are slightly different
+	cliSnapshot := cliWriter.Snapshot()
+	assert.Greater(t, snapshot.Throughput.RequestsPerSecond, 0.0)
+	assert.Greater(t, cliSnapshot.Throughput.RequestsPerSecond, 0.0)
+	snapshot.Throughput = wcrawler.Throughput{}
+	cliSnapshot.Throughput = wcrawler.Throughput{}
+	assert.Equal(t, snapshot, cliSnapshot)
+	assert.Contains(t, cliBuf.String(), "Crawler State:    Finished")
+}
+
+// failingConnector fails the requests to some URLs, taking the given time to fail, and serves the others
+// from a fakeConnector with a response.
+type failingConnector struct {
+	fakeConnector
+	failing map[string]bool
+	timings wcrawler.Timings
+}
+
+func (fc failingConnector) GetLinks(rawURL string) (int, []wcrawler.URLEntity, wcrawler.ResponseInfo, error) {
+	if fc.failing[rawURL] {
+		return 0, nil, wcrawler.ResponseInfo{Timings: wcrawler.Timings{Total: 5 * time.Second}},
+			fmt.Errorf("dial tcp: connection refused")
+	}
+
+	statusCode, links, info, err := fc.fakeConnector.GetLinks(rawURL)
+	info.Timings = fc.timings
+	return statusCode, links, info, err
+}
+
+func TestCrawlerStatsFailedRequests(t *testing.T) {
+	jsonWriter := wcrawler.NewStatsJSONWriter(&bytes.Buffer{}, 2, 3)
+
+	connector := failingConnector{
+		fakeConnector: fakeConnector{pages: fakePages},
+		failing:       map[string]bool{"http://example.com/about": true},
+		timings:       wcrawler.Timings{TTFB: 10 * time.Millisecond, Total: 20 * time.Millisecond},
+	}
+	c, err := wcrawler.NewCrawler(connector, "http://example.com/", 0, &bytes.Buffer{}, jsonWriter, false, false, 2, 3)
+	require.NoError(t, err)
+
+	c.Run()
+
+	// The failed request is counted, but not sampled: it took 5s to fail and had no TTFB
+	snapshot := jsonWriter.Snapshot()
+	assert.Equal(t, 3, snapshot.TotalRequestsCount)
+	assert.Equal(t, 1, snapshot.ErrorCategories["connection"])
+	assert.Equal(t, uint64(2), snapshot.TTFB.Count)
+	assert.Equal(t, uint64(2), snapshot.TotalTime.Count)
+	assert.Equal(t, 0.02, snapshot.TotalTime.Max)
+
+	host := snapshot.Hosts["example.com"]
+	assert.Equal(t, 3, host.Requests)
+	assert.Equal(t, uint64(2), host.TTFB.Count)
+	assert.Equal(t, uint64(2), host.TotalTime.Count)
+}
+
+// fakeGraphListener keeps the last record of each URL and the links it's told about.
+type fakeGraphListener struct {
+	records  map[string]wcrawler.Record
+	links    [][2]int
+	finished bool
+}
+
+func (fl *fakeGraphListener) UpdateRecord(record wcrawler.Record) { fl.records[record.URL] = record }
+func (fl *fakeGraphListener) AddLink(from int, to int)            { fl.links = append(fl.links, [2]int{from, to}) }
+func (fl *fakeGraphListener) Finished()                           { fl.finished = true }
+
+func TestCrawlerGraphListener(t *testing.T) {
+	listener := &fakeGraphListener{records: map[string]wcrawler.Record{}}
+
+	c, err := wcrawler.NewCrawler(fakeConnector{pages: fakePages}, "http://example.com/", 0, &bytes.Buffer{},
+		nil, false, false, 2, 3)
+	require.NoError(t, err)
+	c.SetGraphListener(listener)
+
+	c.Run()
+
+	require.Len(t, listener.records, 3)
+	assert.Equal(t, 200, listener.records["http://example.com/"].StatusCode)
+	assert.Equal(t, 200, listener.records["http://example.com/about"].StatusCode)
+	assert.Equal(t, 404, listener.records["http://example.com/missing"].StatusCode)
+
+	home := listener.records["http://example.com/"].Index
+	about := listener.records["http://example.com/about"].Index
+	missing := listener.records["http://example.com/missing"].Index
+	assert.ElementsMatch(t, [][2]int{{home, about}, {home, missing}, {about, home}}, listener.links)
+	assert.True(t, listener.finished)
+}
+
+func TestCrawlerSummary(t *testing.T) {
+	pages := map[string][]string{
+		"http://example.com/":      {"http://example.com/about", "http://example.com/missing"},
+		"http://example.com/about": {"http://example.com/team"},
+		"http://example.com/team":  {},
+	}
+
+	tests := map[string]struct {
+		depth              int
+		expectedStopReason string
+		expectedFetched    int
+	}{
+		"no depth limit": {
+			depth:              0,
+			expectedStopReason: "Completed",
+			expectedFetched:    4,
+		},
+		"depth limit reached": {
+			depth:              1,
+			expectedStopReason: "MaxDepth",
+			expectedFetched:    3,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			c, err := wcrawler.NewCrawler(fakeConnector{pages: pages}, "http://example.com/", 0, &bytes.Buffer{},
+				nil, false, false, 2, test.depth)
+			require.NoError(t, err)
+
+			c.Run()
+
+			summary := c.Summary(10)
+			assert.Equal(t, test.expectedStopReason, summary.StopReason)
+			assert.Equal(t, 4, summary.PagesDiscovered)
+			assert.Equal(t, test.expectedFetched, summary.PagesFetched)
+			assert.Equal(t, map[string]int{"http_4xx": 1}, summary.ErrorCategories)
+			assert.False(t, summary.FinishedAt.Before(summary.StartedAt))
+		})
+	}
+}
+
+// slowConnector serves pages from a fakeConnector, taking some time for each request
+// and keeping track of the requests made.
+type slowConnector struct {
+	fakeConnector
+	delay time.Duration
+
+	mu         sync.Mutex
+	requests   []string
+	running    int
+	maxRunning int
+}
+
+func (sc *slowConnector) GetLinks(rawURL string) (int, []wcrawler.URLEntity, wcrawler.ResponseInfo, error) {
+	sc.mu.Lock()
+	sc.requests = append(sc.requests, rawURL)
+	sc.running++
+	if sc.running > sc.maxRunning {
+		sc.maxRunning = sc.running
+	}
+	sc.mu.Unlock()
+
+	time.Sleep(sc.delay)
+
+	sc.mu.Lock()
+	sc.running--
+	sc.mu.Unlock()
+
+	return sc.fakeConnector.GetLinks(rawURL)
+}
+
+func (sc *slowConnector) requestsCount() int {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	return len(sc.requests)
+}
+
+var manyPages = map[string][]string{
+	"http://example.com/": {"http://example.com/1", "http://example.com/2", "http://example.com/3",
+		"http://example.com/4", "http://example.com/5", "http://example.com/6", "http://other.com/"},
+	"http://other.com/": {"http://other.com/about"},
+}
+
+func TestCrawlerPauseResume(t *testing.T) {
+	connector := &slowConnector{fakeConnector: fakeConnector{pages: manyPages}, delay: 10 * time.Millisecond}
+	c, err := wcrawler.NewCrawler(connector, "http://example.com/", 0, &bytes.Buffer{}, nil, false, false, 2, 0)
+	require.NoError(t, err)
+
+	c.Pause()
+	assert.True(t, c.Paused())
+
+	done := make(chan struct{})
+	go func() {
+		c.Run()
+		close(done)
+	}()
+
+	time.Sleep(100 * time.Millisecond)
+	assert.Equal(t, 0, connector.requestsCount())
+
+	c.Resume()
+	assert.False(t, c.Paused())
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		require.FailNow(t, "crawler didn't finish after resuming")
+	}
+
+	assert.Equal(t, 9, connector.requestsCount())
+}
+
+func TestCrawlerStop(t *testing.T) {
+	connector := &slowConnector{fakeConnector: fakeConnector{pages: manyPages}, delay: 50 * time.Millisecond}
+	var buf bytes.Buffer
+	c, err := wcrawler.NewCrawler(connector, "http://example.com/", 0, &buf, nil, false, false, 1, 0)
+	require.NoError(t, err)
+
+	done := make(chan struct{})
+	go func() {
+		c.Run()
+		close(done)
+	}()
+
+	// Stop while the seed page is being requested, before its links are
+	time.Sleep(20 * time.Millisecond)
+	c.Stop()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		require.FailNow(t, "crawler didn't finish after stopping")
+	}
+
+	assert.Equal(t, 1, connector.requestsCount())
+
+	// The records found so far are saved
+	rm := wcrawler.NewRecordManager()
+	require.NoError(t, rm.LoadFromReader(&buf))
+	assert.Equal(t, 8, rm.Count())
+	assert.Equal(t, "Stopped", rm.Header.StopReason)
+
+	summary := c.Summary(10)
+	assert.Equal(t, "Stopped", summary.StopReason)
+	assert.Equal(t, 1, summary.PagesFetched)
+}
+
+func TestCrawlerSetWorkers(t *testing.T) {
+	connector := &slowConnector{fakeConnector: fakeConnector{pages: manyPages}, delay: 50 * time.Millisecond}
+	c, err := wcrawler.NewCrawler(connector, "http://example.com/", 0, &bytes.Buffer{}, nil, false, false, 1, 0)
+	require.NoError(t, err)
+
+	require.Error(t, c.SetWorkers(0))
+
+	done := make(chan struct{})
+	go func() {
+		c.Run()
+		close(done)
+	}()
+
+	require.NoError(t, c.SetWorkers(4))
+	assert.Equal(t, 4, c.Workers())
+
+	<-done
+
+	assert.Equal(t, 9, connector.requestsCount())
+	assert.Greater(t, connector.maxRunning, 1)
+	assert.LessOrEqual(t, connector.maxRunning, 4)
+}
+
+// deepPages has pages up to depth 2
+var deepPages = map[string][]string{
+	"http://example.com/":  {"http://example.com/a", "http://example.com/b"},
+	"http://example.com/a": {"http://example.com/a/1", "http://example.com/a/2"},
+	"http://example.com/b": {"http://example.com/b/1"},
+}
+
+func TestCrawlerSetMaxDepth(t *testing.T) {
+	tests := map[string]struct {
+		// depth is set while the crawler is paused, before any request is made
+		depth int
+		// raisedDepth is set once the third request is being made (if not zero)
+		raisedDepth        int
+		expectedRequests   int
+		expectedStopReason string
+	}{
+		"lowered": {depth: 1, expectedRequests: 3, expectedStopReason: "MaxDepth"},
+		"raised":  {depth: 1, raisedDepth: 2, expectedRequests: 6, expectedStopReason: "Completed"},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			connector := &slowConnector{fakeConnector: fakeConnector{pages: deepPages}, delay: 20 * time.Millisecond}
+			c, err := wcrawler.NewCrawler(connector, "http://example.com/", 0, &bytes.Buffer{}, nil, false, false, 1, 0)
+			require.NoError(t, err)
+
+			require.Error(t, c.SetMaxDepth(-1))
+
+			c.Pause()
+
+			done := make(chan struct{})
+			go func() {
+				c.Run()
+				close(done)
+			}()
+
+			require.NoError(t, c.SetMaxDepth(test.depth))
+			assert.Equal(t, test.depth, c.MaxDepth())
+			c.Resume()
+
+			if test.raisedDepth != 0 {
+				for connector.requestsCount() < 3 {
+					time.Sleep(time.Millisecond)
+				}
+				require.NoError(t, c.SetMaxDepth(test.raisedDepth))
+			}
+
+			<-done
+
+			assert.Equal(t, test.expectedRequests, connector.requestsCount())
+			assert.Equal(t, test.expectedStopReason, c.Summary(0).StopReason)
+		})
+	}
+}
+
+func TestCrawlerBlockHost(t *testing.T) {
+	connector := &slowConnector{fakeConnector: fakeConnector{pages: manyPages}}
+	var buf bytes.Buffer
+	c, err := wcrawler.NewCrawler(connector, "http://example.com/", 0, &buf, nil, false, false, 2, 0)
+	require.NoError(t, err)
+
+	c.BlockHost("other.com")
+	assert.Equal(t, []string{"other.com"}, c.BlockedHosts())
+
+	c.Run()
+
+	assert.Equal(t, 7, connector.requestsCount())
+
+	rm := wcrawler.NewRecordManager()
+	err = rm.LoadFromReader(&buf)
+	require.NoError(t, err)
+
+	// The page is recorded but never requested
+	r, ok := rm.Get("http://other.com/")
+	require.True(t, ok)
+	assert.Equal(t, 0, r.StatusCode)
+}
@@ -0,0 +1,216 @@
+package wcrawler
+
+import (
+	"fmt"
+	"io"
+	"sort"
+)
+
+// CrawlDiff represents the differences between two crawls.
+// Records are matched by URL.
+type CrawlDiff struct {
+	AddedPages    []string       `json:"addedPages"`
+	RemovedPages  []string       `json:"removedPages"`
+	StatusChanges []StatusChange `json:"statusChanges"`
+	DepthChanges  []DepthChange  `json:"depthChanges"`
+	AddedLinks    []LinkChange   `json:"addedLinks"`
+	RemovedLinks  []LinkChange   `json:"removedLinks"`
+	// NewErrors lists pages that failed in the new crawl but didn't fail in the old one
+	NewErrors []ErrorChange `json:"newErrors"`
+}
+
+// StatusChange represents a page whose status code changed between crawls.
+type StatusChange struct {
+	URL           string `json:"url"`
+	OldStatusCode int    `json:"oldStatusCode"`
+	NewStatusCode int    `json:"newStatusCode"`
+}
+
+// DepthChange represents a page found at a different depth between crawls.
+type DepthChange struct {
+	URL      string `json:"url"`
+	OldDepth int    `json:"oldDepth"`
+	NewDepth int    `json:"newDepth"`
+}
+
+// LinkChange represents a link between two pages.
+type LinkChange struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// ErrorChange represents a page that started failing.
+type ErrorChange struct {
+	URL   string `json:"url"`
+	Error string `json:"error"`
+}
+
+// DiffRecords compares two crawls and returns the differences found.
+func DiffRecords(oldRM *RecordManager, newRM *RecordManager) CrawlDiff {
+	diff := CrawlDiff{
+		AddedPages:    []string{},
+		RemovedPages:  []string{},
+		StatusChanges: []StatusChange{},
+		DepthChanges:  []DepthChange{},
+		AddedLinks:    []LinkChange{},
+		RemovedLinks:  []LinkChange{},
+		NewErrors:     []ErrorChange{},
+	}
+
+	for _, rawURL := range sortedURLs(newRM) {
+		newR := newRM.Records[rawURL]
+		oldR, ok := oldRM.Records[rawURL]
+
+		if !ok {
+			diff.AddedPages = append(diff.AddedPages, rawURL)
+		} else {
+			if fetched(oldR) && fetched(newR) && oldR.StatusCode != newR.StatusCode {
+				diff.StatusChanges = append(diff.StatusChanges,
+					StatusChange{URL: rawURL, OldStatusCode: oldR.StatusCode, NewStatusCode: newR.StatusCode})
+			}
+
+			if oldR.Depth != newR.Depth {
+				diff.DepthChanges = append(diff.DepthChanges,
+					DepthChange{URL: rawURL, OldDepth: oldR.Depth, NewDepth: newR.Depth})
+			}
+		}
+
+		if failed(newR) && (!ok || !failed(oldR)) {
+			diff.NewErrors = append(diff.NewErrors, ErrorChange{URL: rawURL, Error: failureReason(newR)})
+		}
+	}
+
+	for _, rawURL := range sortedURLs(oldRM) {
+		if _, ok := newRM.Records[rawURL]; !ok {
+			diff.RemovedPages = append(diff.RemovedPages, rawURL)
+		}
+	}
+
+	oldLinks := linksSet(oldRM)
+	newLinks := linksSet(newRM)
+
+	for link := range newLinks {
+		if !oldLinks[link] {
+			diff.AddedLinks = append(diff.AddedLinks, link)
+		}
+	}
+
+	for link := range oldLinks {
+		if !newLinks[link] {
+			diff.RemovedLinks = append(diff.RemovedLinks, link)
+		}
+	}
+
+	sortLinks(diff.AddedLinks)
+	sortLinks(diff.RemovedLinks)
+
+	return diff
+}
+
+// HasRegressions returns true if there are pages that started failing in the new crawl.
+func (d CrawlDiff) HasRegressions() bool {
+	return len(d.NewErrors) != 0
+}
+
+// WriteText writes the differences in a human readable format.
+func (d CrawlDiff) WriteText(w io.Writer) error {
+	var err error
+	write := func(format string, a ...interface{}) {
+		if err == nil {
+			_, err = fmt.Fprintf(w, format, a...)
+		}
+	}
+
+	write("Added pages: %d\n", len(d.AddedPages))
+	for _, u := range d.AddedPages {
+		write("+ %s\n", u)
+	}
+
+	write("\nRemoved pages: %d\n", len(d.RemovedPages))
+	for _, u := range d.RemovedPages {
+		write("- %s\n", u)
+	}
+
+	write("\nStatus code changes: %d\n", len(d.StatusChanges))
+	for _, sc := range d.StatusChanges {
+		write("~ %s: %d -> %d\n", sc.URL, sc.OldStatusCode, sc.NewStatusCode)
+	}
+
+	write("\nNew errors: %d\n", len(d.NewErrors))
+	for _, ec := range d.NewErrors {
+		write("! %s: %s\n", ec.URL, ec.Error)
+	}
+
+	write("\nDepth changes: %d\n", len(d.DepthChanges))
+	for _, dc := range d.DepthChanges {
+		write("~ %s: %d -> %d\n", dc.URL, dc.OldDepth, dc.NewDepth)
+	}
+
+	write("\nAdded links: %d\n", len(d.AddedLinks))
+	for _, l := range d.AddedLinks {
+		write("+ %s -> %s\n", l.From, l.To)
+	}
+
+	write("\nRemoved links: %d\n", len(d.RemovedLinks))
+	for _, l := range d.RemovedLinks {
+		write("- %s -> %s\n", l.From, l.To)
+	}
+
+	return err
+}
+
+// fetched returns true if a request has been made for the record.
+// Records beyond the max depth are kept but never requested (status code is zero and there is no error).
+func fetched(r Record) bool {
+	return r.StatusCode != 0 || r.ErrString != ""
+}
+
+// failed returns true if the request for the record failed or returned an error status code.
+func failed(r Record) bool {
+	return r.ErrString != "" || r.StatusCode >= 400
+}
+
+// failureReason returns a description of why the request for the record failed.
+func failureReason(r Record) string {
+	if r.ErrString != "" {
+		return r.ErrString
+	}
+
+	return fmt.Sprintf("status code received: %d", r.StatusCode)
+}
+
+// sortedURLs returns the URLs of all records sorted alphabetically.
+func sortedURLs(rm *RecordManager) []string {
+	urls := make([]string, 0, len(rm.Records))
+	for u := range rm.Records {
+		urls = append(urls, u)
+	}
+	sort.Strings(urls)
+
+	return urls
+}
+
+// linksSet returns all links between records identified by their URLs.
+func linksSet(rm *RecordManager) map[LinkChange]bool {
+	urls := rm.URLsByIndex()
+	links := map[LinkChange]bool{}
+
+	for _, r := range rm.Records {
+		for edge := range r.Edges {
+			if to, ok := urls[edge]; ok {
+				links[LinkChange{From: r.URL, To: to}] = true
+			}
+		}
+	}
+
+	return links
+}
+
+func sortLinks(links []LinkChange) {
+	sort.Slice(links, func(i, j int) bool {
+		if links[i].From != links[j].From {
+			return links[i].From < links[j].From
+		}
+		return links[i].To < links[j].To
+	})
+}
@@ -0,0 +1,65 @@
+package wcrawler_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/gustavooferreira/wcrawler"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiffRecords(t *testing.T) {
+	oldRM := wcrawler.NewRecordManager()
+	addEntries(oldRM)
+
+	newRM := wcrawler.NewRecordManager()
+	addEntries(newRM)
+
+	// about page is now broken, main page no longer links to example123.com
+	// and a new page is linked from the about page
+	err := newRM.Update("http://example1.com/about", 404, nil)
+	require.NoError(t, err)
+	delete(newRM.Records, "http://example123.com/")
+	r := newRM.Records["http://example1.com/main"]
+	r.Edges.Remove(3)
+	newRM.AddRecord(wcrawler.RMEntry{
+		ParentURL:  "http://example1.com/about",
+		URL:        wcrawler.URLEntity{NetLoc: "example1.com", Raw: "http://example1.com/new"},
+		Depth:      2,
+		StatusCode: 200,
+	})
+
+	diff := wcrawler.DiffRecords(oldRM, newRM)
+
+	assert.Equal(t, []string{"http://example1.com/new"}, diff.AddedPages)
+	assert.Equal(t, []string{"http://example123.com/"}, diff.RemovedPages)
+	assert.Equal(t, []wcrawler.StatusChange{{URL: "http://example1.com/about", OldStatusCode: 200, NewStatusCode: 404}}, diff.StatusChanges)
+	assert.Equal(t, []wcrawler.ErrorChange{{URL: "http://example1.com/about", Error: "status code received: 404"}}, diff.NewErrors)
+	assert.Equal(t, []wcrawler.LinkChange{{From: "http://example1.com/about", To: "http://example1.com/new"}}, diff.AddedLinks)
+	assert.Equal(t, []wcrawler.LinkChange{{From: "http://example1.com/main", To: "http://example123.com/"}}, diff.RemovedLinks)
+	assert.Empty(t, diff.DepthChanges)
+	assert.True(t, diff.HasRegressions())
+
+	var buf bytes.Buffer
+	err = diff.WriteText(&buf)
+	require.NoError(t, err)
+	assert.Contains(t, buf.String(), "~ http://example1.com/about: 200 -> 404\n")
+}
+
+func TestDiffRecordsNoChanges(t *testing.T) {
+	oldRM := wcrawler.NewRecordManager()
+	addEntries(oldRM)
+
+	newRM := wcrawler.NewRecordManager()
+	addEntries(newRM)
+
+	diff := wcrawler.DiffRecords(oldRM, newRM)
+
+	assert.Empty(t, diff.AddedPages)
+	assert.Empty(t, diff.RemovedPages)
+	assert.Empty(t, diff.StatusChanges)
+	assert.Empty(t, diff.AddedLinks)
+	assert.Empty(t, diff.RemovedLinks)
+	assert.False(t, diff.HasRegressions())
+}
@@ -2,7 +2,10 @@ package wcrawler
 
 import (
 	"encoding/json"
+	"fmt"
 	"sort"
+	"strconv"
+	"time"
 )
 
 // Record represents an entry in the RecordManager (internal state).
@@ -21,6 +24,24 @@ type Record struct {
 	Edges      EdgesSet `json:"edges"`
 	StatusCode int      `json:"statusCode"`
 	ErrString  string   `json:"errString,omitempty"`
+	// ErrorCategory represents why the request failed (errors and unexpected status codes alike)
+	ErrorCategory ErrorCategory `json:"errorCategory,omitempty"`
+	// Size represents the number of bytes downloaded (only the body of successful responses is read)
+	Size int64 `json:"size,omitempty"`
+	// ContentType represents the media type of the response (e.g. "text/html")
+	ContentType string `json:"contentType,omitempty"`
+	// Timings represents the time spent in each phase of the request (durations in nanoseconds)
+	Timings *Timings `json:"timings,omitempty"`
+	// FetchedAt represents when the request for this URL was made (nil if never requested)
+	FetchedAt *time.Time `json:"fetchedAt,omitempty"`
+	// Sources lists the crawls this record came from (only set when crawls are merged)
+	Sources []string `json:"sources,omitempty"`
+	// Anchors keeps the text of the <a> tags linking to other records.
+	// Key is the index of the record the link points to.
+	Anchors map[int]string `json:"anchors,omitempty"`
+	// Scores holds graph metrics computed after the crawl (e.g. pagerank).
+	// Only filled when the analysis results are written back.
+	Scores map[string]float64 `json:"scores,omitempty"`
 }
 
 // RMEntry represents an entry in the RecordManager (external interface).
@@ -38,22 +59,77 @@ type URLEntity struct {
 	NetLoc string
 	// Raw represents the entire URL
 	Raw string
+	// AnchorText represents the text of the <a> tag where the URL was found (if any)
+	AnchorText string
+}
+
+// ResponseInfo holds metadata about the response received for a request.
+type ResponseInfo struct {
+	// BytesRead represents the number of bytes read from the response body
+	BytesRead int64
+	// ContentType represents the media type of the response (e.g. "text/html")
+	ContentType string
+	// Timings represents the time spent in each phase of the request
+	Timings Timings
+}
+
+// Timings represents the time spent in each phase of an HTTP request.
+// DNSLookup, TCPConnect and TLSHandshake are zero when an existing connection is reused.
+type Timings struct {
+	DNSLookup    time.Duration `json:"dnsLookup"`
+	TCPConnect   time.Duration `json:"tcpConnect"`
+	TLSHandshake time.Duration `json:"tlsHandshake"`
+	// TTFB represents the time from the start of the request until the first byte of the response
+	TTFB time.Duration `json:"ttfb"`
+	// ContentTransfer represents the time from the first byte until the body is fully read
+	ContentTransfer time.Duration `json:"contentTransfer"`
+	// Total represents the time from the start of the request until the body is fully read
+	Total time.Duration `json:"total"`
+	// ConnReused indicates whether the request was made on a previously opened connection
+	ConnReused bool `json:"connReused"`
 }
 
 // Task is what gets sent to the channel for workers to pull data from the web.
 type Task struct {
-	URL   string
-	Depth int
+	URL   string `json:"url"`
+	Host  string `json:"host"`
+	Depth int    `json:"depth"`
 }
 
 // Result is what workers return in a channel.
 type Result struct {
 	ParentURL  string
 	StatusCode int
+	Info       ResponseInfo
 	Links      []URLEntity
 	// Depth of the ParentURL
 	Depth int
 	Err   error
+	// Skipped is set when no request was made because the host got blocked
+	Skipped bool
+}
+
+// FetchEvent represents a request made by the crawler, as reported to the StatsManager.
+type FetchEvent struct {
+	Time          time.Time     `json:"time"`
+	URL           string        `json:"url"`
+	Host          string        `json:"host"`
+	Depth         int           `json:"depth"`
+	StatusCode    int           `json:"statusCode"`
+	ErrorCategory ErrorCategory `json:"errorCategory"`
+	Error         string        `json:"error,omitempty"`
+	Size          int64         `json:"size"`
+	Duration      time.Duration `json:"duration"`
+}
+
+// String returns the event as a line of a requests log: status (or error category), time taken and URL.
+func (e FetchEvent) String() string {
+	status := strconv.Itoa(e.StatusCode)
+	if e.Error != "" {
+		status = e.ErrorCategory.String()
+	}
+
+	return fmt.Sprintf("%-10s %7.3fs  %s", status, e.Duration.Seconds(), e.URL)
 }
 
 type EdgesSet map[int]struct{}
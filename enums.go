@@ -14,6 +14,8 @@ const (
 	AppState_Running
 	// AppState_Finished represents the 'finish' state.
 	AppState_Finished
+	// AppState_Paused represents the 'paused' state (no new requests are dispatched).
+	AppState_Paused
 )
 
 var appStateToString = map[AppState]string{
@@ -21,6 +23,7 @@ var appStateToString = map[AppState]string{
 	AppState_IDLE:     "IDLE",
 	AppState_Running:  "Running",
 	AppState_Finished: "Finished",
+	AppState_Paused:   "Paused",
 }
 
 var appStateToEnum = map[string]AppState{
@@ -28,6 +31,7 @@ var appStateToEnum = map[string]AppState{
 	"IDLE":     AppState_IDLE,
 	"Running":  AppState_Running,
 	"Finished": AppState_Finished,
+	"Paused":   AppState_Paused,
 }
 
 // String returns the string representation of AppState.
@@ -50,3 +54,202 @@ func (as *AppState) Parse(state string) error {
 	*as = value
 	return nil
 }
+
+// StopReason represents the reason why the crawler stopped.
+type StopReason int
+
+const (
+	// StopReason_Unknown represents an unknown reason (e.g. the crawler hasn't stopped yet).
+	StopReason_Unknown StopReason = iota
+	// StopReason_Completed represents a crawl where every link found was followed.
+	StopReason_Completed
+	// StopReason_MaxDepth represents a crawl where links beyond the max depth were left unvisited.
+	StopReason_MaxDepth
+	// StopReason_Stopped represents a crawl stopped before every link found was followed (see Crawler.Stop).
+	StopReason_Stopped
+)
+
+var stopReasonToString = map[StopReason]string{
+	StopReason_Unknown:   "Unknown",
+	StopReason_Completed: "Completed",
+	StopReason_MaxDepth:  "MaxDepth",
+	StopReason_Stopped:   "Stopped",
+}
+
+var stopReasonToEnum = map[string]StopReason{
+	"Unknown":   StopReason_Unknown,
+	"Completed": StopReason_Completed,
+	"MaxDepth":  StopReason_MaxDepth,
+	"Stopped":   StopReason_Stopped,
+}
+
+// String returns the string representation of StopReason.
+func (sr StopReason) String() string {
+	reason, ok := stopReasonToString[sr]
+	if !ok {
+		return "Unknown"
+	}
+
+	return reason
+}
+
+// Parse parses a string into StopReason returning an error if string passed cannot be parsed into a valid reason.
+func (sr *StopReason) Parse(reason string) error {
+	value, ok := stopReasonToEnum[reason]
+	if !ok {
+		return fmt.Errorf("couldn't parse stop reason")
+	}
+
+	*sr = value
+	return nil
+}
+
+// Description returns a human readable description of StopReason.
+func (sr StopReason) Description() string {
+	switch sr {
+	case StopReason_Completed:
+		return "all links found were visited"
+	case StopReason_MaxDepth:
+		return "max depth reached, links beyond it were not visited"
+	case StopReason_Stopped:
+		return "stopped, links left in the queue were not visited"
+	default:
+		return "unknown"
+	}
+}
+
+// ProgressFormat represents the format of the progress lines written by StatsProgressWriter.
+type ProgressFormat int
+
+const (
+	// ProgressFormat_Unknown represents an unknown format.
+	ProgressFormat_Unknown ProgressFormat = iota
+	// ProgressFormat_Plain represents single-line human readable logs.
+	ProgressFormat_Plain
+	// ProgressFormat_JSON represents JSON-lines events.
+	ProgressFormat_JSON
+)
+
+var progressFormatToString = map[ProgressFormat]string{
+	ProgressFormat_Unknown: "Unknown",
+	ProgressFormat_Plain:   "plain",
+	ProgressFormat_JSON:    "json",
+}
+
+var progressFormatToEnum = map[string]ProgressFormat{
+	"Unknown": ProgressFormat_Unknown,
+	"plain":   ProgressFormat_Plain,
+	"json":    ProgressFormat_JSON,
+}
+
+// String returns the string representation of ProgressFormat.
+func (pf ProgressFormat) String() string {
+	format, ok := progressFormatToString[pf]
+	if !ok {
+		return "Unknown"
+	}
+
+	return format
+}
+
+// Parse parses a string into ProgressFormat returning an error if string passed cannot be parsed into a valid format.
+func (pf *ProgressFormat) Parse(format string) error {
+	value, ok := progressFormatToEnum[format]
+	if !ok {
+		return fmt.Errorf("couldn't parse progress format")
+	}
+
+	*pf = value
+	return nil
+}
+
+// ErrorCategory represents the kind of failure of a request.
+type ErrorCategory int
+
+const (
+	// ErrorCategory_None represents a successful request.
+	ErrorCategory_None ErrorCategory = iota
+	// ErrorCategory_DNS represents a failure to resolve the host name.
+	ErrorCategory_DNS
+	// ErrorCategory_Connection represents a failure to connect (e.g. connection refused or reset).
+	ErrorCategory_Connection
+	// ErrorCategory_TLS represents a failure in the TLS handshake (e.g. invalid certificate).
+	ErrorCategory_TLS
+	// ErrorCategory_Timeout represents a request that timed out.
+	ErrorCategory_Timeout
+	// ErrorCategory_Redirect represents a request that failed while following redirects.
+	ErrorCategory_Redirect
+	// ErrorCategory_InvalidURL represents a URL that couldn't be requested (e.g. unsupported scheme).
+	ErrorCategory_InvalidURL
+	// ErrorCategory_Parse represents a failure reading or parsing the response body.
+	ErrorCategory_Parse
+	// ErrorCategory_HTTP4xx represents a 4xx status code received.
+	ErrorCategory_HTTP4xx
+	// ErrorCategory_HTTP5xx represents a 5xx status code received.
+	ErrorCategory_HTTP5xx
+	// ErrorCategory_HTTPOther represents any other unexpected status code received (e.g. 1xx or 3xx).
+	ErrorCategory_HTTPOther
+	// ErrorCategory_Other represents any other failure.
+	ErrorCategory_Other
+)
+
+var errorCategoryToString = map[ErrorCategory]string{
+	ErrorCategory_None:       "none",
+	ErrorCategory_DNS:        "dns",
+	ErrorCategory_Connection: "connection",
+	ErrorCategory_TLS:        "tls",
+	ErrorCategory_Timeout:    "timeout",
+	ErrorCategory_Redirect:   "redirect",
+	ErrorCategory_InvalidURL: "invalid_url",
+	ErrorCategory_Parse:      "parse",
+	ErrorCategory_HTTP4xx:    "http_4xx",
+	ErrorCategory_HTTP5xx:    "http_5xx",
+	ErrorCategory_HTTPOther:  "http_other",
+	ErrorCategory_Other:      "other",
+}
+
+var errorCategoryToEnum = map[string]ErrorCategory{
+	"none":        ErrorCategory_None,
+	"dns":         ErrorCategory_DNS,
+	"connection":  ErrorCategory_Connection,
+	"tls":         ErrorCategory_TLS,
+	"timeout":     ErrorCategory_Timeout,
+	"redirect":    ErrorCategory_Redirect,
+	"invalid_url": ErrorCategory_InvalidURL,
+	"parse":       ErrorCategory_Parse,
+	"http_4xx":    ErrorCategory_HTTP4xx,
+	"http_5xx":    ErrorCategory_HTTP5xx,
+	"http_other":  ErrorCategory_HTTPOther,
+	"other":       ErrorCategory_Other,
+}
+
+// String returns the string representation of ErrorCategory.
+func (ec ErrorCategory) String() string {
+	category, ok := errorCategoryToString[ec]
+	if !ok {
+		return "other"
+	}
+
+	return category
+}
+
+// Parse parses a string into ErrorCategory returning an error if string passed cannot be parsed into a valid category.
+func (ec *ErrorCategory) Parse(category string) error {
+	value, ok := errorCategoryToEnum[category]
+	if !ok {
+		return fmt.Errorf("couldn't parse error category")
+	}
+
+	*ec = value
+	return nil
+}
+
+// MarshalText implements encoding.TextMarshaler, so categories are saved by name.
+func (ec ErrorCategory) MarshalText() ([]byte, error) {
+	return []byte(ec.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (ec *ErrorCategory) UnmarshalText(text []byte) error {
+	return ec.Parse(string(text))
+}
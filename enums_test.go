@@ -25,6 +25,10 @@ func TestReturningAppStateString(t *testing.T) {
 			input:          wcrawler.AppState_Finished,
 			expectedOutput: "Finished",
 		},
+		"test 'Paused' state": {
+			input:          wcrawler.AppState_Paused,
+			expectedOutput: "Paused",
+		},
 		"test 'Unknown' state": {
 			input:          wcrawler.AppState_Unknown,
 			expectedOutput: "Unknown",
@@ -86,3 +90,44 @@ func TestParsingAppStateString(t *testing.T) {
 		})
 	}
 }
+
+func TestParsingStopReasonString(t *testing.T) {
+	tests := map[string]struct {
+		input          string
+		expectedErr    bool
+		expectedOutput wcrawler.StopReason
+	}{
+		"test 'Completed' reason": {
+			input:          "Completed",
+			expectedOutput: wcrawler.StopReason_Completed,
+		},
+		"test 'MaxDepth' reason": {
+			input:          "MaxDepth",
+			expectedOutput: wcrawler.StopReason_MaxDepth,
+		},
+		"test 'Stopped' reason": {
+			input:          "Stopped",
+			expectedOutput: wcrawler.StopReason_Stopped,
+		},
+		"test missing reason": {
+			input:       "qwueyqwie",
+			expectedErr: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var value wcrawler.StopReason
+			err := value.Parse(test.input)
+
+			if test.expectedErr {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, test.expectedOutput, value)
+			assert.Equal(t, test.input, value.String())
+		})
+	}
+}
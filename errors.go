@@ -0,0 +1,101 @@
+package wcrawler
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"net"
+	"net/url"
+	"strings"
+	"syscall"
+)
+
+// FetchError represents a failed request, classified into a category.
+type FetchError struct {
+	Category ErrorCategory
+	// Err is the underlying cause
+	Err error
+}
+
+func (e *FetchError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *FetchError) Unwrap() error {
+	return e.Err
+}
+
+// ClassifyError returns the category of a request's outcome.
+// Errors are classified by type when possible, and by their message otherwise
+// (e.g. errors loaded from a file). Without an error, the status code is used.
+func ClassifyError(statusCode int, err error) ErrorCategory {
+	if err == nil {
+		switch {
+		case statusCode >= 200 && statusCode < 300:
+			return ErrorCategory_None
+		case statusCode >= 400 && statusCode < 500:
+			return ErrorCategory_HTTP4xx
+		case statusCode >= 500 && statusCode < 600:
+			return ErrorCategory_HTTP5xx
+		default:
+			return ErrorCategory_HTTPOther
+		}
+	}
+
+	var fetchErr *FetchError
+	if errors.As(err, &fetchErr) {
+		return fetchErr.Category
+	}
+
+	// Order matters, e.g. a DNS error can also be a timeout
+	var dnsErr *net.DNSError
+	var netErr net.Error
+	var recordHeaderErr tls.RecordHeaderError
+	var unknownAuthorityErr x509.UnknownAuthorityError
+	var hostnameErr x509.HostnameError
+	var certificateInvalidErr x509.CertificateInvalidError
+	var urlErr *url.Error
+	var opErr *net.OpError
+
+	switch {
+	case errors.As(err, &dnsErr):
+		return ErrorCategory_DNS
+	case errors.Is(err, context.DeadlineExceeded), errors.As(err, &netErr) && netErr.Timeout():
+		return ErrorCategory_Timeout
+	case errors.As(err, &recordHeaderErr), errors.As(err, &unknownAuthorityErr),
+		errors.As(err, &hostnameErr), errors.As(err, &certificateInvalidErr):
+		return ErrorCategory_TLS
+	case errors.Is(err, syscall.ECONNREFUSED), errors.Is(err, syscall.ECONNRESET),
+		errors.Is(err, syscall.ECONNABORTED), errors.As(err, &opErr):
+		return ErrorCategory_Connection
+	case errors.As(err, &urlErr) && urlErr.Op == "parse":
+		return ErrorCategory_InvalidURL
+	}
+
+	return classifyErrorMessage(err.Error())
+}
+
+// classifyErrorMessage returns the category of an error based on its message.
+func classifyErrorMessage(msg string) ErrorCategory {
+	msg = strings.ToLower(msg)
+
+	switch {
+	case strings.Contains(msg, "no such host"), strings.Contains(msg, "server misbehaving"):
+		return ErrorCategory_DNS
+	case strings.Contains(msg, "timeout"), strings.Contains(msg, "deadline exceeded"):
+		return ErrorCategory_Timeout
+	case strings.Contains(msg, "tls:"), strings.Contains(msg, "x509:"):
+		return ErrorCategory_TLS
+	case strings.Contains(msg, "connection refused"), strings.Contains(msg, "connection reset"),
+		strings.Contains(msg, "eof"):
+		return ErrorCategory_Connection
+	case strings.Contains(msg, "redirects"):
+		return ErrorCategory_Redirect
+	case strings.Contains(msg, "unsupported protocol scheme"), strings.Contains(msg, "invalid url"),
+		strings.Contains(msg, "missing protocol scheme"):
+		return ErrorCategory_InvalidURL
+	default:
+		return ErrorCategory_Other
+	}
+}
@@ -0,0 +1,99 @@
+package wcrawler_test
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gustavooferreira/wcrawler"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClassifyError(t *testing.T) {
+	tests := map[string]struct {
+		statusCode       int
+		err              error
+		expectedCategory wcrawler.ErrorCategory
+	}{
+		"success":               {statusCode: 200, expectedCategory: wcrawler.ErrorCategory_None},
+		"not found":             {statusCode: 404, expectedCategory: wcrawler.ErrorCategory_HTTP4xx},
+		"server error":          {statusCode: 503, expectedCategory: wcrawler.ErrorCategory_HTTP5xx},
+		"redirect not followed": {statusCode: 301, expectedCategory: wcrawler.ErrorCategory_HTTPOther},
+		"dns error": {
+			err:              &net.DNSError{Err: "no such host", Name: "example.invalid"},
+			expectedCategory: wcrawler.ErrorCategory_DNS,
+		},
+		"wrapped dns error": {
+			err:              fmt.Errorf("get: %w", &net.DNSError{Err: "no such host", Name: "example.invalid"}),
+			expectedCategory: wcrawler.ErrorCategory_DNS,
+		},
+		"fetch error": {
+			err:              &wcrawler.FetchError{Category: wcrawler.ErrorCategory_Parse, Err: errors.New("bad html")},
+			expectedCategory: wcrawler.ErrorCategory_Parse,
+		},
+		"timeout message": {
+			err:              errors.New("Get \"http://example.com\": context deadline exceeded (Client.Timeout exceeded while awaiting headers)"),
+			expectedCategory: wcrawler.ErrorCategory_Timeout,
+		},
+		"redirects message": {
+			err:              errors.New("Get \"http://example.com\": stopped after 10 redirects"),
+			expectedCategory: wcrawler.ErrorCategory_Redirect,
+		},
+		"unsupported scheme message": {
+			err:              errors.New("Get \"ftp://example.com\": unsupported protocol scheme \"ftp\""),
+			expectedCategory: wcrawler.ErrorCategory_InvalidURL,
+		},
+		"unknown error": {
+			err:              errors.New("something went wrong"),
+			expectedCategory: wcrawler.ErrorCategory_Other,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			category := wcrawler.ClassifyError(test.statusCode, test.err)
+			assert.Equal(t, test.expectedCategory, category)
+		})
+	}
+}
+
+func TestClassifyRequestErrors(t *testing.T) {
+	slowServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+	}))
+	defer slowServer.Close()
+
+	tlsServer := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer tlsServer.Close()
+
+	// Grab a free port and close it, so connections are refused
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	closedAddr := ln.Addr().String()
+	ln.Close()
+
+	tests := map[string]struct {
+		url              string
+		timeout          time.Duration
+		expectedCategory wcrawler.ErrorCategory
+	}{
+		"timeout":            {url: slowServer.URL, timeout: 50 * time.Millisecond, expectedCategory: wcrawler.ErrorCategory_Timeout},
+		"invalid cert":       {url: tlsServer.URL, timeout: 5 * time.Second, expectedCategory: wcrawler.ErrorCategory_TLS},
+		"connection refused": {url: "http://" + closedAddr, timeout: 5 * time.Second, expectedCategory: wcrawler.ErrorCategory_Connection},
+		"invalid url":        {url: "http://exa mple.com/%zz", timeout: 5 * time.Second, expectedCategory: wcrawler.ErrorCategory_InvalidURL},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			wc := wcrawler.NewWebClient(&http.Client{Timeout: test.timeout})
+			_, _, _, err := wc.GetLinks(test.url)
+			require.Error(t, err)
+			assert.Equal(t, test.expectedCategory, wcrawler.ClassifyError(0, err))
+		})
+	}
+}
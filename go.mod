@@ -8,4 +8,5 @@ require (
 	github.com/spf13/cobra v1.1.3
 	github.com/stretchr/testify v1.7.0
 	golang.org/x/net v0.0.0-20210222171744-9060382bd457
+	golang.org/x/term v0.10.0
 )
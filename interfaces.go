@@ -1,12 +1,13 @@
 package wcrawler
 
 import (
+	"io"
 	"time"
 )
 
 // Connector describes the connector interface.
 type Connector interface {
-	GetLinks(rawURL string) (statusCode int, links []URLEntity, latency time.Duration, err error)
+	GetLinks(rawURL string) (statusCode int, links []URLEntity, info ResponseInfo, err error)
 }
 
 // StatsManager represents a tracker of statistics related to the crawler.
@@ -22,10 +23,56 @@ type StatsManager interface {
 	IncDecErrorsCount(value int)
 	SetWorkersRunning(value int)
 	IncDecWorkersRunning(value int)
+	SetTotalWorkersCount(value int)
+	SetMaxDepthLevel(value int)
+	IncDecHostWorkersRunning(host string, value int)
 	SetTotalRequestsCount(value int)
 	IncDecTotalRequestsCount(value int)
 	SetDepth(value int)
 	IncDecDepth(value int)
 	AddLatencySample(value time.Duration)
+	AddErrorEntry(value string)
+	AddFetchEvent(event FetchEvent)
+	AddResponseSample(host string, statusCode int, info ResponseInfo, err error)
+	// RunOutputFlusher is run in its own goroutine by the Crawler and should only
+	// return once there is nothing else to output (i.e., the state is set to AppState_Finished).
 	RunOutputFlusher()
 }
+
+// Controller steers a running crawl.
+type Controller interface {
+	// Pause stops dispatching new requests. Requests in flight still complete.
+	Pause()
+	// Resume resumes dispatching requests.
+	Resume()
+	Paused() bool
+	// SetWorkers changes the number of workers making concurrent requests.
+	SetWorkers(n int) error
+	Workers() int
+	// BlockHost stops requests to a host. Links to the host are still recorded.
+	BlockHost(host string)
+	BlockedHosts() []string
+	// SetMaxDepth changes the depth of recursion (0 means no limit).
+	SetMaxDepth(depth int) error
+	MaxDepth() int
+}
+
+// Inspector looks into the state of a running crawl.
+type Inspector interface {
+	// Frontier returns the links waiting to be requested, with a sample of up to n links.
+	Frontier(n int) Frontier
+	// WriteRecords writes a snapshot of the records collected so far in JSON format.
+	WriteRecords(w io.Writer) error
+}
+
+// GraphListener gets the changes to the graph of pages as the crawl makes them.
+// Methods are called from the Merger's goroutine, so they must not block.
+type GraphListener interface {
+	// UpdateRecord is called when a page is found, and again once it's requested.
+	UpdateRecord(record Record)
+	// AddLink is called when a link is found from a page to another (by record index).
+	// It can be called more than once for the same link.
+	AddLink(from int, to int)
+	// Finished is called once the crawl is over.
+	Finished()
+}
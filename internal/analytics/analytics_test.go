@@ -0,0 +1,160 @@
+package analytics_test
+
+import (
+	"testing"
+
+	"github.com/gustavooferreira/wcrawler"
+	"github.com/gustavooferreira/wcrawler/internal/analytics"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPageRank(t *testing.T) {
+	g := analytics.NewGraph(newRecordManager())
+
+	rank := g.PageRank(0.85, 100, 1e-9)
+	require.Len(t, rank, g.Len())
+
+	sum := 0.0
+	for _, r := range rank {
+		sum += r
+	}
+	assert.InDelta(t, 1.0, sum, 1e-6)
+
+	// The homepage is linked from both pages, so it must rank the highest
+	home, ok := g.Position("http://example.com/")
+	require.True(t, ok)
+	for pos := range rank {
+		if pos != home {
+			assert.Greater(t, rank[home], rank[pos])
+		}
+	}
+}
+
+func TestStronglyConnectedComponents(t *testing.T) {
+	g := analytics.NewGraph(newRecordManager())
+
+	components := g.StronglyConnectedComponents()
+
+	sizes := map[int]int{}
+	for _, c := range components {
+		sizes[len(c)]++
+	}
+
+	// home <-> about <-> contact form a cycle, all other pages stand alone
+	assert.Equal(t, map[int]int{3: 1, 1: 3}, sizes)
+}
+
+func TestAnalyze(t *testing.T) {
+	tests := map[string]struct {
+		sameHost            bool
+		expectedUnreachable []string
+	}{
+		"all hosts": {
+			sameHost:            false,
+			expectedUnreachable: []string{"http://example.com/orphan", "http://other.com/orphan"},
+		},
+		"same host": {
+			sameHost:            true,
+			expectedUnreachable: []string{"http://example.com/orphan"},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			opts := analytics.DefaultOptions()
+			opts.SameHost = test.sameHost
+
+			report := analytics.Analyze(analytics.NewGraph(newRecordManager()), opts)
+
+			assert.Equal(t, 6, report.Summary.Pages)
+			assert.Equal(t, 5, report.Summary.Links)
+			assert.Equal(t, map[int]int{0: 3, 1: 2, 2: 1}, report.Summary.DepthDistribution)
+			assert.Equal(t, test.expectedUnreachable, report.Unreachable)
+			assert.Equal(t, []string{"http://external.com/"}, report.DeadEnds)
+			assert.Equal(t, "http://example.com/", report.Nodes[0].URL)
+		})
+	}
+}
+
+func TestWriteBack(t *testing.T) {
+	rm := newRecordManager()
+	report := analytics.Analyze(analytics.NewGraph(rm), analytics.DefaultOptions())
+
+	analytics.WriteBack(rm, report)
+
+	r, ok := rm.Get("http://example.com/")
+	require.True(t, ok)
+	assert.Equal(t, report.Nodes[0].PageRank, r.Scores["pagerank"])
+	assert.Equal(t, 2.0, r.Scores["inDegree"])
+}
+
+// newRecordManager returns a RecordManager with the following graph:
+// home -> about, home -> contact, about -> home, contact -> home, contact -> external
+// plus two orphan pages (one in the same host as home and another in a different host).
+func newRecordManager() *wcrawler.RecordManager {
+	rm := wcrawler.NewRecordManager()
+
+	entries := []wcrawler.RMEntry{
+		{ParentURL: "", URL: wcrawler.URLEntity{NetLoc: "example.com", Raw: "http://example.com/"}, Depth: 0, StatusCode: 200},
+		{ParentURL: "http://example.com/", URL: wcrawler.URLEntity{NetLoc: "example.com", Raw: "http://example.com/about"}, Depth: 1, StatusCode: 200},
+		{ParentURL: "http://example.com/", URL: wcrawler.URLEntity{NetLoc: "example.com", Raw: "http://example.com/contact"}, Depth: 1, StatusCode: 200},
+		{ParentURL: "http://example.com/contact", URL: wcrawler.URLEntity{NetLoc: "external.com", Raw: "http://external.com/"}, Depth: 2, StatusCode: 200},
+		{ParentURL: "", URL: wcrawler.URLEntity{NetLoc: "example.com", Raw: "http://example.com/orphan"}, Depth: 0},
+		{ParentURL: "", URL: wcrawler.URLEntity{NetLoc: "other.com", Raw: "http://other.com/orphan"}, Depth: 0},
+	}
+
+	for _, e := range entries {
+		rm.AddRecord(e)
+	}
+
+	rm.AddEdge("http://example.com/about", "http://example.com/")
+	rm.AddEdge("http://example.com/contact", "http://example.com/")
+
+	return rm
+}
+
+func TestKShortestPaths(t *testing.T) {
+	rm := newRecordManager()
+	// Add an alternative (longer) route: home -> about -> contact
+	rm.AddEdge("http://example.com/about", "http://example.com/contact")
+
+	g := analytics.NewGraph(rm)
+
+	home, _ := g.Position("http://example.com/")
+	about, _ := g.Position("http://example.com/about")
+	contact, _ := g.Position("http://example.com/contact")
+	external, _ := g.Position("http://external.com/")
+	orphan, _ := g.Position("http://example.com/orphan")
+
+	tests := map[string]struct {
+		from          int
+		to            int
+		k             int
+		expectedPaths [][]int
+	}{
+		"single path": {
+			from: home, to: external, k: 1,
+			expectedPaths: [][]int{{home, contact, external}},
+		},
+		"two paths": {
+			from: home, to: external, k: 3,
+			expectedPaths: [][]int{{home, contact, external}, {home, about, contact, external}},
+		},
+		"same node": {
+			from: home, to: home, k: 1,
+			expectedPaths: [][]int{{home}},
+		},
+		"no path": {
+			from: home, to: orphan, k: 2,
+			expectedPaths: nil,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			paths := g.KShortestPaths(test.from, test.to, test.k)
+			assert.Equal(t, test.expectedPaths, paths)
+		})
+	}
+}
@@ -0,0 +1,87 @@
+package analytics
+
+// StronglyConnectedComponents returns the strongly connected components of the graph
+// using Tarjan's algorithm. Each component is a list of node positions.
+// The algorithm is implemented iteratively, so it doesn't blow the stack on deep graphs.
+func (g *Graph) StronglyConnectedComponents() [][]int {
+	n := g.Len()
+
+	index := make([]int, n)
+	lowlink := make([]int, n)
+	onStack := make([]bool, n)
+	for i := range index {
+		index[i] = -1
+	}
+
+	var components [][]int
+	var stack []int
+	counter := 0
+
+	// frame represents a node being visited and the next edge to explore
+	type frame struct {
+		node int
+		edge int
+	}
+
+	for root := 0; root < n; root++ {
+		if index[root] != -1 {
+			continue
+		}
+
+		callStack := []frame{{node: root}}
+		index[root] = counter
+		lowlink[root] = counter
+		counter++
+		stack = append(stack, root)
+		onStack[root] = true
+
+		for len(callStack) != 0 {
+			top := &callStack[len(callStack)-1]
+			v := top.node
+
+			if top.edge < len(g.Out[v]) {
+				w := g.Out[v][top.edge]
+				top.edge++
+
+				if index[w] == -1 {
+					index[w] = counter
+					lowlink[w] = counter
+					counter++
+					stack = append(stack, w)
+					onStack[w] = true
+					callStack = append(callStack, frame{node: w})
+				} else if onStack[w] && index[w] < lowlink[v] {
+					lowlink[v] = index[w]
+				}
+				continue
+			}
+
+			// All edges explored, check if v is the root of a component
+			if lowlink[v] == index[v] {
+				var component []int
+				for {
+					w := stack[len(stack)-1]
+					stack = stack[:len(stack)-1]
+					onStack[w] = false
+					component = append(component, w)
+					if w == v {
+						break
+					}
+				}
+				components = append(components, component)
+			}
+
+			callStack = callStack[:len(callStack)-1]
+
+			// Propagate lowlink to the parent
+			if len(callStack) != 0 {
+				parent := callStack[len(callStack)-1].node
+				if lowlink[v] < lowlink[parent] {
+					lowlink[parent] = lowlink[v]
+				}
+			}
+		}
+	}
+
+	return components
+}
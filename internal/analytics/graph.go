@@ -0,0 +1,124 @@
+// Package analytics provides graph algorithms to compute metrics over the crawled web graph.
+package analytics
+
+import (
+	"sort"
+
+	"github.com/gustavooferreira/wcrawler"
+)
+
+// Graph represents the crawled web graph in a form suitable for running graph algorithms.
+// Nodes are referenced by their position in the Nodes slice (not by the record index).
+type Graph struct {
+	// Nodes holds all records sorted by their index.
+	Nodes []wcrawler.Record
+	// Out holds, for each node, the positions of the nodes it links to.
+	Out [][]int
+	// In holds, for each node, the positions of the nodes linking to it.
+	In [][]int
+	// Seed is the position of the initial URL of the crawl (-1 if not found).
+	Seed int
+
+	// maps record index to position
+	indexToPos map[int]int
+	// maps URL to position
+	urlToPos map[string]int
+}
+
+// NewGraph builds a new Graph from the records kept in the RecordManager.
+// Edges pointing to indexes not present in the RecordManager are ignored.
+func NewGraph(rm *wcrawler.RecordManager) *Graph {
+	records := rm.Dump()
+
+	g := &Graph{
+		Nodes:      make([]wcrawler.Record, 0, len(records)),
+		Seed:       -1,
+		indexToPos: make(map[int]int, len(records)),
+		urlToPos:   make(map[string]int, len(records)),
+	}
+
+	for _, r := range records {
+		g.Nodes = append(g.Nodes, r)
+	}
+
+	// Sort nodes so the output of the algorithms is deterministic
+	sort.Slice(g.Nodes, func(i, j int) bool {
+		return g.Nodes[i].Index < g.Nodes[j].Index
+	})
+
+	for pos, r := range g.Nodes {
+		g.indexToPos[r.Index] = pos
+		g.urlToPos[r.URL] = pos
+
+		if r.InitPoint && g.Seed == -1 {
+			g.Seed = pos
+		}
+	}
+
+	g.Out = make([][]int, len(g.Nodes))
+	g.In = make([][]int, len(g.Nodes))
+
+	for pos, r := range g.Nodes {
+		for _, edge := range r.Edges.Dump() {
+			target, ok := g.indexToPos[edge]
+			if !ok {
+				continue
+			}
+
+			g.Out[pos] = append(g.Out[pos], target)
+			g.In[target] = append(g.In[target], pos)
+		}
+	}
+
+	return g
+}
+
+// Len returns the number of nodes in the graph.
+func (g *Graph) Len() int {
+	return len(g.Nodes)
+}
+
+// Position returns the position of the node with the given URL.
+func (g *Graph) Position(rawURL string) (int, bool) {
+	pos, ok := g.urlToPos[rawURL]
+	return pos, ok
+}
+
+// PositionByIndex returns the position of the node with the given record index.
+func (g *Graph) PositionByIndex(index int) (int, bool) {
+	pos, ok := g.indexToPos[index]
+	return pos, ok
+}
+
+// Reachable returns which nodes can be reached from the start node following the edges.
+// If sameHost is true, only nodes on the same host as the start node are traversed.
+func (g *Graph) Reachable(start int, sameHost bool) []bool {
+	visited := make([]bool, g.Len())
+	if start < 0 || start >= g.Len() {
+		return visited
+	}
+
+	host := g.Nodes[start].Host
+	visited[start] = true
+	queue := []int{start}
+
+	for len(queue) != 0 {
+		current := queue[0]
+		queue = queue[1:]
+
+		for _, next := range g.Out[current] {
+			if visited[next] {
+				continue
+			}
+
+			if sameHost && g.Nodes[next].Host != host {
+				continue
+			}
+
+			visited[next] = true
+			queue = append(queue, next)
+		}
+	}
+
+	return visited
+}
@@ -0,0 +1,154 @@
+package analytics
+
+import "sort"
+
+// ShortestPath returns the shortest path (fewest clicks) between two nodes using a breadth-first search.
+// The path includes both ends. Returns nil if there is no path.
+func (g *Graph) ShortestPath(from int, to int) []int {
+	return g.shortestPath(from, to, nil, nil)
+}
+
+// KShortestPaths returns up to k loopless shortest paths between two nodes, ordered by length,
+// using Yen's algorithm. Each path includes both ends.
+func (g *Graph) KShortestPaths(from int, to int, k int) [][]int {
+	if k <= 0 {
+		return nil
+	}
+
+	first := g.ShortestPath(from, to)
+	if first == nil {
+		return nil
+	}
+
+	paths := [][]int{first}
+	var candidates [][]int
+
+	for len(paths) < k {
+		previous := paths[len(paths)-1]
+
+		for i := 0; i < len(previous)-1; i++ {
+			spurNode := previous[i]
+			rootPath := previous[:i+1]
+
+			// Remove the edges that would lead to paths already found with the same root
+			blockedEdges := map[[2]int]bool{}
+			for _, p := range paths {
+				if len(p) > i+1 && equalPaths(p[:i+1], rootPath) {
+					blockedEdges[[2]int{p[i], p[i+1]}] = true
+				}
+			}
+
+			// Remove the nodes in the root path (except the spur node) so paths stay loopless
+			blockedNodes := map[int]bool{}
+			for _, node := range rootPath[:i] {
+				blockedNodes[node] = true
+			}
+
+			spurPath := g.shortestPath(spurNode, to, blockedNodes, blockedEdges)
+			if spurPath == nil {
+				continue
+			}
+
+			candidate := make([]int, 0, len(rootPath)+len(spurPath)-1)
+			candidate = append(candidate, rootPath[:i]...)
+			candidate = append(candidate, spurPath...)
+
+			if !containsPath(paths, candidate) && !containsPath(candidates, candidate) {
+				candidates = append(candidates, candidate)
+			}
+		}
+
+		if len(candidates) == 0 {
+			break
+		}
+
+		sort.SliceStable(candidates, func(i, j int) bool {
+			return len(candidates[i]) < len(candidates[j])
+		})
+
+		paths = append(paths, candidates[0])
+		candidates = candidates[1:]
+	}
+
+	return paths
+}
+
+// shortestPath runs a breadth-first search skipping the blocked nodes and edges.
+func (g *Graph) shortestPath(from int, to int, blockedNodes map[int]bool, blockedEdges map[[2]int]bool) []int {
+	if from < 0 || from >= g.Len() || to < 0 || to >= g.Len() {
+		return nil
+	}
+
+	if from == to {
+		return []int{from}
+	}
+
+	parent := make([]int, g.Len())
+	for i := range parent {
+		parent[i] = -1
+	}
+	parent[from] = from
+
+	queue := []int{from}
+
+	for len(queue) != 0 {
+		current := queue[0]
+		queue = queue[1:]
+
+		for _, next := range g.Out[current] {
+			if parent[next] != -1 || blockedNodes[next] || blockedEdges[[2]int{current, next}] {
+				continue
+			}
+
+			parent[next] = current
+
+			if next == to {
+				// Walk back the path
+				path := []int{to}
+				for node := current; node != from; node = parent[node] {
+					path = append(path, node)
+				}
+				path = append(path, from)
+
+				for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+					path[i], path[j] = path[j], path[i]
+				}
+
+				return path
+			}
+
+			queue = append(queue, next)
+		}
+	}
+
+	return nil
+}
+
+// AnchorText returns the text of the link between two nodes (empty if unknown).
+func (g *Graph) AnchorText(from int, to int) string {
+	return g.Nodes[from].Anchors[g.Nodes[to].Index]
+}
+
+func equalPaths(a []int, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+func containsPath(paths [][]int, path []int) bool {
+	for _, p := range paths {
+		if equalPaths(p, path) {
+			return true
+		}
+	}
+
+	return false
+}
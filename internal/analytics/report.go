@@ -0,0 +1,197 @@
+package analytics
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"text/tabwriter"
+
+	"github.com/gustavooferreira/wcrawler"
+)
+
+// Options configures the analysis.
+type Options struct {
+	// Damping factor used by PageRank (usually 0.85)
+	Damping float64
+	// Max number of iterations for the iterative algorithms (PageRank and HITS)
+	MaxIterations int
+	// Convergence tolerance for the iterative algorithms
+	Tolerance float64
+	// SameHost restricts the reachability analysis to the seed's host
+	SameHost bool
+}
+
+// DefaultOptions returns the options normally used for the analysis.
+func DefaultOptions() Options {
+	return Options{Damping: 0.85, MaxIterations: 100, Tolerance: 1e-6}
+}
+
+// NodeMetrics holds the metrics computed for a single page.
+type NodeMetrics struct {
+	URL        string  `json:"url"`
+	Host       string  `json:"host"`
+	Depth      int     `json:"depth"`
+	StatusCode int     `json:"statusCode"`
+	InDegree   int     `json:"inDegree"`
+	OutDegree  int     `json:"outDegree"`
+	PageRank   float64 `json:"pagerank"`
+	Hub        float64 `json:"hub"`
+	Authority  float64 `json:"authority"`
+	// Component is the ID of the strongly connected component this page belongs to
+	Component int `json:"component"`
+}
+
+// Summary holds the metrics computed for the graph as a whole.
+type Summary struct {
+	Pages                int         `json:"pages"`
+	Links                int         `json:"links"`
+	Components           int         `json:"components"`
+	LargestComponentSize int         `json:"largestComponentSize"`
+	DepthDistribution    map[int]int `json:"depthDistribution"`
+}
+
+// Report represents the result of the analysis.
+type Report struct {
+	Summary Summary       `json:"summary"`
+	Nodes   []NodeMetrics `json:"nodes"`
+	// Unreachable lists pages that cannot be reached from the seed (within scope)
+	Unreachable []string `json:"unreachable"`
+	// DeadEnds lists pages fetched successfully which don't link anywhere
+	DeadEnds []string `json:"deadEnds"`
+}
+
+// Analyze runs all the algorithms over the graph and returns a report.
+// Nodes in the report are sorted by PageRank in descending order.
+func Analyze(g *Graph, opts Options) Report {
+	n := g.Len()
+
+	report := Report{
+		Summary:     Summary{Pages: n, DepthDistribution: map[int]int{}},
+		Nodes:       make([]NodeMetrics, n),
+		Unreachable: []string{},
+		DeadEnds:    []string{},
+	}
+
+	pagerank := g.PageRank(opts.Damping, opts.MaxIterations, opts.Tolerance)
+	hubs, authorities := g.HITS(opts.MaxIterations, opts.Tolerance)
+
+	components := g.StronglyConnectedComponents()
+	componentOf := make([]int, n)
+	for id, component := range components {
+		for _, pos := range component {
+			componentOf[pos] = id
+		}
+
+		if len(component) > report.Summary.LargestComponentSize {
+			report.Summary.LargestComponentSize = len(component)
+		}
+	}
+	report.Summary.Components = len(components)
+
+	reachable := g.Reachable(g.Seed, opts.SameHost)
+
+	for pos, r := range g.Nodes {
+		report.Nodes[pos] = NodeMetrics{
+			URL:        r.URL,
+			Host:       r.Host,
+			Depth:      r.Depth,
+			StatusCode: r.StatusCode,
+			InDegree:   len(g.In[pos]),
+			OutDegree:  len(g.Out[pos]),
+			PageRank:   pagerank[pos],
+			Hub:        hubs[pos],
+			Authority:  authorities[pos],
+			Component:  componentOf[pos],
+		}
+
+		report.Summary.Links += len(g.Out[pos])
+		report.Summary.DepthDistribution[r.Depth]++
+
+		inScope := !opts.SameHost || g.Seed == -1 || r.Host == g.Nodes[g.Seed].Host
+		if inScope && !reachable[pos] {
+			report.Unreachable = append(report.Unreachable, r.URL)
+		}
+
+		if r.StatusCode >= 200 && r.StatusCode < 300 && len(g.Out[pos]) == 0 {
+			report.DeadEnds = append(report.DeadEnds, r.URL)
+		}
+	}
+
+	sort.SliceStable(report.Nodes, func(i, j int) bool {
+		return report.Nodes[i].PageRank > report.Nodes[j].PageRank
+	})
+
+	return report
+}
+
+// WriteBack stores the computed scores in the records of the RecordManager,
+// so they can be saved together with the crawl data and used by the viewer.
+func WriteBack(rm *wcrawler.RecordManager, report Report) {
+	for _, nm := range report.Nodes {
+		r, ok := rm.Get(nm.URL)
+		if !ok {
+			continue
+		}
+
+		r.Scores = map[string]float64{
+			"pagerank":  nm.PageRank,
+			"hub":       nm.Hub,
+			"authority": nm.Authority,
+			"inDegree":  float64(nm.InDegree),
+			"outDegree": float64(nm.OutDegree),
+		}
+
+		rm.Records[nm.URL] = r
+	}
+}
+
+// WriteTable writes the report in a human readable format.
+// Only the top N pages (by PageRank) are listed. A top of zero lists all pages.
+func WriteTable(w io.Writer, report Report, top int) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	s := report.Summary
+	fmt.Fprintf(tw, "Pages:\t%d\n", s.Pages)
+	fmt.Fprintf(tw, "Links:\t%d\n", s.Links)
+	fmt.Fprintf(tw, "Strongly connected components:\t%d (largest: %d)\n", s.Components, s.LargestComponentSize)
+	fmt.Fprintf(tw, "Unreachable pages:\t%d\n", len(report.Unreachable))
+	fmt.Fprintf(tw, "Dead-end pages:\t%d\n", len(report.DeadEnds))
+
+	depths := make([]int, 0, len(s.DepthDistribution))
+	for d := range s.DepthDistribution {
+		depths = append(depths, d)
+	}
+	sort.Ints(depths)
+
+	fmt.Fprintf(tw, "\nDEPTH\tPAGES\n")
+	for _, d := range depths {
+		fmt.Fprintf(tw, "%d\t%d\n", d, s.DepthDistribution[d])
+	}
+
+	nodes := report.Nodes
+	if top > 0 && top < len(nodes) {
+		nodes = nodes[:top]
+	}
+
+	fmt.Fprintf(tw, "\nPAGERANK\tHUB\tAUTHORITY\tIN\tOUT\tDEPTH\tSTATUS\tURL\n")
+	for _, nm := range nodes {
+		fmt.Fprintf(tw, "%.5f\t%.5f\t%.5f\t%d\t%d\t%d\t%d\t%s\n",
+			nm.PageRank, nm.Hub, nm.Authority, nm.InDegree, nm.OutDegree, nm.Depth, nm.StatusCode, nm.URL)
+	}
+
+	if len(report.Unreachable) != 0 {
+		fmt.Fprintf(tw, "\nUnreachable pages:\n")
+		for _, u := range report.Unreachable {
+			fmt.Fprintf(tw, "- %s\n", u)
+		}
+	}
+
+	if len(report.DeadEnds) != 0 {
+		fmt.Fprintf(tw, "\nDead-end pages:\n")
+		for _, u := range report.DeadEnds {
+			fmt.Fprintf(tw, "- %s\n", u)
+		}
+	}
+
+	return tw.Flush()
+}
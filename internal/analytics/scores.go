@@ -0,0 +1,123 @@
+package analytics
+
+import "math"
+
+// PageRank computes the PageRank of every node in the graph using the power iteration method.
+// The rank of dangling nodes (nodes without outgoing edges) is distributed evenly across all nodes.
+// Iteration stops after maxIterations or when the L1 difference between iterations drops below tolerance.
+func (g *Graph) PageRank(damping float64, maxIterations int, tolerance float64) []float64 {
+	n := g.Len()
+	if n == 0 {
+		return nil
+	}
+
+	rank := make([]float64, n)
+	for i := range rank {
+		rank[i] = 1 / float64(n)
+	}
+
+	next := make([]float64, n)
+
+	for iter := 0; iter < maxIterations; iter++ {
+		danglingSum := 0.0
+		for i := 0; i < n; i++ {
+			if len(g.Out[i]) == 0 {
+				danglingSum += rank[i]
+			}
+		}
+
+		base := (1-damping)/float64(n) + damping*danglingSum/float64(n)
+		for i := range next {
+			next[i] = base
+		}
+
+		for i := 0; i < n; i++ {
+			if len(g.Out[i]) == 0 {
+				continue
+			}
+
+			share := damping * rank[i] / float64(len(g.Out[i]))
+			for _, j := range g.Out[i] {
+				next[j] += share
+			}
+		}
+
+		diff := 0.0
+		for i := range rank {
+			diff += math.Abs(next[i] - rank[i])
+		}
+
+		rank, next = next, rank
+
+		if diff < tolerance {
+			break
+		}
+	}
+
+	return rank
+}
+
+// HITS computes the hub and authority scores of every node in the graph.
+// Both score vectors are normalized to unit length on every iteration.
+func (g *Graph) HITS(maxIterations int, tolerance float64) (hubs []float64, authorities []float64) {
+	n := g.Len()
+	if n == 0 {
+		return nil, nil
+	}
+
+	hubs = make([]float64, n)
+	authorities = make([]float64, n)
+	for i := 0; i < n; i++ {
+		hubs[i] = 1
+		authorities[i] = 1
+	}
+
+	for iter := 0; iter < maxIterations; iter++ {
+		newAuth := make([]float64, n)
+		for i := 0; i < n; i++ {
+			for _, j := range g.In[i] {
+				newAuth[i] += hubs[j]
+			}
+		}
+		normalize(newAuth)
+
+		newHubs := make([]float64, n)
+		for i := 0; i < n; i++ {
+			for _, j := range g.Out[i] {
+				newHubs[i] += newAuth[j]
+			}
+		}
+		normalize(newHubs)
+
+		diff := 0.0
+		for i := 0; i < n; i++ {
+			diff += math.Abs(newAuth[i]-authorities[i]) + math.Abs(newHubs[i]-hubs[i])
+		}
+
+		hubs, authorities = newHubs, newAuth
+
+		if diff < tolerance {
+			break
+		}
+	}
+
+	return hubs, authorities
+}
+
+// normalize scales the vector to unit length (L2 norm).
+// A vector with all zeros is left untouched.
+func normalize(v []float64) {
+	sum := 0.0
+	for _, x := range v {
+		sum += x * x
+	}
+
+	if sum == 0 {
+		return
+	}
+
+	norm := math.Sqrt(sum)
+	for i := range v {
+		v[i] /= norm
+	}
+}
@@ -0,0 +1,145 @@
+package graph
+
+import (
+	"fmt"
+	"net"
+	"sort"
+
+	"golang.org/x/net/publicsuffix"
+)
+
+// How pages can be aggregated in the graph.
+const (
+	// AggregateHost collapses the pages into one node per host (e.g. "blog.example.com")
+	AggregateHost = "host"
+	// AggregateDomain collapses the pages into one node per registered domain (e.g. "example.com" for
+	// "blog.example.com" and "www.example.com")
+	AggregateDomain = "domain"
+)
+
+// Aggregations lists how pages can be aggregated.
+var Aggregations = []string{AggregateHost, AggregateDomain}
+
+// hostNode accumulates the pages of a host while aggregating.
+type hostNode struct {
+	node     Node
+	statuses map[string]int
+	broken   int
+}
+
+// Aggregate collapses the pages into one node per host or registered domain (AggregateHost or AggregateDomain),
+// with a link between two of them weighted by the number of links between their pages. Links between pages of the
+// same host aren't kept. If aggregate is empty, the elements are returned as is.
+//
+// The node of a host has the host as ID and URL, its registered domain as domain, the number of pages and their total
+// size, the lowest depth of its pages, the most common status class of its pages, and the number of links to and from
+// other hosts as links count and in-degree. If some of the pages couldn't be fetched, the error says how many.
+func Aggregate(elements Elements, aggregate string) Elements {
+	if aggregate != AggregateHost && aggregate != AggregateDomain {
+		return elements
+	}
+
+	hosts := map[string]*hostNode{}
+	// keys are the keys of the nodes in the order they were first seen, keysByID the key of every page
+	var keys []string
+	keysByID := make(map[string]string, len(elements.Nodes))
+
+	for _, n := range elements.Nodes {
+		key := n.Domain
+		if aggregate == AggregateDomain {
+			key = RegisteredDomain(n.Domain)
+		}
+		keysByID[n.ID] = key
+
+		h, ok := hosts[key]
+		if !ok {
+			h = &hostNode{
+				node:     Node{ID: key, URL: key, Domain: RegisteredDomain(key), Depth: n.Depth},
+				statuses: map[string]int{},
+			}
+			hosts[key] = h
+			keys = append(keys, key)
+		}
+
+		h.node.Pages++
+		h.node.Size += n.Size
+		if n.Depth < h.node.Depth {
+			h.node.Depth = n.Depth
+		}
+		h.statuses[n.StatusClass]++
+		if contains(ErrorStatusClasses, n.StatusClass) {
+			h.broken++
+		}
+	}
+
+	weights := map[[2]string]int{}
+	for _, l := range elements.Links {
+		source, ok := keysByID[l.Source]
+		if !ok {
+			continue
+		}
+		target, ok := keysByID[l.Target]
+		if !ok || source == target {
+			continue
+		}
+
+		weights[[2]string{source, target}]++
+		hosts[source].node.LinksCount++
+		hosts[target].node.InDegree++
+	}
+
+	nodes := make([]Node, 0, len(keys))
+	for _, key := range keys {
+		h := hosts[key]
+		h.node.StatusClass = mostCommon(h.statuses)
+		if h.broken != 0 {
+			h.node.Error = fmt.Sprintf("%d of %d pages couldn't be fetched", h.broken, h.node.Pages)
+		}
+		nodes = append(nodes, h.node)
+	}
+
+	links := make([]Link, 0, len(weights))
+	for pair, weight := range weights {
+		links = append(links, Link{Source: pair[0], Target: pair[1], Weight: weight})
+	}
+	sort.Slice(links, func(i, j int) bool {
+		if links[i].Source != links[j].Source {
+			return links[i].Source < links[j].Source
+		}
+		return links[i].Target < links[j].Target
+	})
+
+	return Elements{Nodes: nodes, Links: links}
+}
+
+// mostCommon returns the status class with the most pages, the first one in statusClasses on a tie.
+func mostCommon(statuses map[string]int) string {
+	best := ""
+	for _, class := range statusClasses {
+		if statuses[class] > statuses[best] {
+			best = class
+		}
+	}
+
+	return best
+}
+
+// RegisteredDomain returns the registered domain of the host (the public suffix and the label before it, e.g.
+// "example.co.uk" for "www.example.co.uk"), without the port. Hosts without one (e.g. IP addresses and "localhost")
+// are returned as they are.
+func RegisteredDomain(host string) string {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+
+	if net.ParseIP(host) != nil {
+		return host
+	}
+
+	domain, err := publicsuffix.EffectiveTLDPlusOne(host)
+	if err != nil {
+		return host
+	}
+
+	return domain
+}
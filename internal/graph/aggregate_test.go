@@ -0,0 +1,86 @@
+package graph_test
+
+import (
+	"testing"
+
+	"github.com/gustavooferreira/wcrawler/internal/graph"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAggregate(t *testing.T) {
+	elements := graph.NewElements(newRecordManager())
+
+	t.Run("host", func(t *testing.T) {
+		aggregated := graph.Aggregate(elements, graph.AggregateHost)
+
+		require.Len(t, aggregated.Nodes, 2)
+		assert.Equal(t, graph.Node{ID: "example.com", URL: "example.com", Domain: "example.com", Pages: 3, Depth: 0,
+			StatusClass: "2xx", Error: "1 of 3 pages couldn't be fetched", LinksCount: 1}, aggregated.Nodes[0])
+		assert.Equal(t, graph.Node{ID: "blog.example.com", URL: "blog.example.com", Domain: "example.com", Pages: 2, Depth: 2,
+			StatusClass: "2xx", Error: "1 of 2 pages couldn't be fetched", InDegree: 1}, aggregated.Nodes[1])
+
+		// Links between pages of the same host aren't kept
+		assert.Equal(t, []graph.Link{{Source: "example.com", Target: "blog.example.com", Weight: 1}}, aggregated.Links)
+	})
+
+	t.Run("domain", func(t *testing.T) {
+		aggregated := graph.Aggregate(elements, graph.AggregateDomain)
+
+		require.Len(t, aggregated.Nodes, 1)
+		assert.Equal(t, "example.com", aggregated.Nodes[0].ID)
+		assert.Equal(t, 5, aggregated.Nodes[0].Pages)
+		assert.Empty(t, aggregated.Links)
+	})
+
+	t.Run("weights", func(t *testing.T) {
+		elements := graph.Elements{
+			Nodes: []graph.Node{
+				{ID: "0", Domain: "example.com", StatusClass: "2xx"},
+				{ID: "1", Domain: "example.com", StatusClass: "2xx"},
+				{ID: "2", Domain: "cdn.example.net", StatusClass: "2xx"},
+				{ID: "3", Domain: "fonts.example.org", StatusClass: "unvisited"},
+			},
+			Links: []graph.Link{
+				{Source: "0", Target: "2"}, {Source: "1", Target: "2"}, {Source: "0", Target: "3"},
+				{Source: "2", Target: "0"}, {Source: "0", Target: "1"},
+				// Links to nodes that aren't in the graph are left out
+				{Source: "0", Target: "9"},
+			},
+		}
+
+		aggregated := graph.Aggregate(elements, graph.AggregateHost)
+		assert.Equal(t, []graph.Link{
+			{Source: "cdn.example.net", Target: "example.com", Weight: 1},
+			{Source: "example.com", Target: "cdn.example.net", Weight: 2},
+			{Source: "example.com", Target: "fonts.example.org", Weight: 1},
+		}, aggregated.Links)
+		assert.Equal(t, 3, aggregated.Nodes[0].LinksCount)
+		assert.Equal(t, 1, aggregated.Nodes[0].InDegree)
+		assert.Equal(t, graph.StatusClassUnvisited, aggregated.Nodes[2].StatusClass)
+	})
+
+	t.Run("none", func(t *testing.T) {
+		assert.Equal(t, elements, graph.Aggregate(elements, ""))
+	})
+}
+
+func TestRegisteredDomain(t *testing.T) {
+	tests := map[string]struct {
+		host           string
+		expectedDomain string
+	}{
+		"domain":           {host: "example.com", expectedDomain: "example.com"},
+		"subdomain":        {host: "www.blog.example.com", expectedDomain: "example.com"},
+		"multi-label tld":  {host: "www.example.co.uk", expectedDomain: "example.co.uk"},
+		"port":             {host: "www.example.com:8080", expectedDomain: "example.com"},
+		"ip address":       {host: "127.0.0.1:8080", expectedDomain: "127.0.0.1"},
+		"no public suffix": {host: "localhost", expectedDomain: "localhost"},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.expectedDomain, graph.RegisteredDomain(test.host))
+		})
+	}
+}
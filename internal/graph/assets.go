@@ -0,0 +1,51 @@
+package graph
+
+import (
+	"embed"
+	"errors"
+	"strings"
+)
+
+// LibraryVersion is the version of the 3d-force-graph library used to render the graph.
+const LibraryVersion = "1.73.3"
+
+// LibraryCDN is where the 3d-force-graph library is loaded from when it's not inlined.
+const LibraryCDN = "https://unpkg.com/3d-force-graph@" + LibraryVersion + "/dist/3d-force-graph.min.js"
+
+// libraryFile is the path of the vendored 3d-force-graph library (see 'make assets').
+const libraryFile = "assets/3d-force-graph.min.js"
+
+// ErrLibraryMissing is returned when the page would use the embedded 3d-force-graph library but it wasn't vendored
+// when the binary was built.
+var ErrLibraryMissing = errors.New("the 3d-force-graph library isn't embedded in this build " +
+	"(run 'make assets' and rebuild, or use the CDN)")
+
+//go:embed assets
+var assets embed.FS
+
+// embeddedLibrary returns the vendored 3d-force-graph library, ready to be inlined in a script tag.
+// ok is false if the library wasn't vendored when the binary was built.
+func embeddedLibrary() (library string, ok bool) {
+	data, err := assets.ReadFile(libraryFile)
+	if err != nil || len(data) == 0 {
+		return "", false
+	}
+
+	// The script would end early if the library contained a closing script tag (e.g. in a string)
+	return strings.ReplaceAll(string(data), "</script", `<\/script`), true
+}
+
+// LibraryEmbedded returns true if the 3d-force-graph library is embedded in the binary.
+func LibraryEmbedded() bool {
+	_, ok := embeddedLibrary()
+	return ok
+}
+
+// checkLibrary returns ErrLibraryMissing if the library isn't embedded, unless it's loaded from the CDN.
+func checkLibrary(useCDN bool) error {
+	if !useCDN && !LibraryEmbedded() {
+		return ErrLibraryMissing
+	}
+
+	return nil
+}
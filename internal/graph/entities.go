@@ -1,18 +1,41 @@
 package graph
 
+import "time"
+
 type Elements struct {
-	Links []Link `json:"links,omitempty"`
-	Nodes []Node `json:"nodes,omitempty"`
+	Links []Link `json:"links"`
+	Nodes []Node `json:"nodes"`
 }
 
 type Link struct {
 	Source string `json:"source,omitempty"`
 	Target string `json:"target,omitempty"`
+	// Weight is the number of links between the pages of two hosts, in aggregated graphs (see Aggregate)
+	Weight int `json:"weight,omitempty"`
 }
 
 type Node struct {
-	ID         string `json:"id,omitempty"`
-	Domain     string `json:"domain,omitempty"`
-	URL        string `json:"url,omitempty"`
-	LinksCount int    `json:"linksCount,omitempty"`
+	ID     string `json:"id,omitempty"`
+	Domain string `json:"domain,omitempty"`
+	URL    string `json:"url,omitempty"`
+	// LinksCount is the number of links on the page (out-degree)
+	LinksCount int `json:"linksCount,omitempty"`
+	// InDegree is the number of pages linking to the page
+	InDegree    int    `json:"inDegree,omitempty"`
+	Depth       int    `json:"depth"`
+	StatusCode  int    `json:"statusCode,omitempty"`
+	StatusClass string `json:"statusClass,omitempty"`
+	Error       string `json:"error,omitempty"`
+	ContentType string `json:"contentType,omitempty"`
+	Size        int64  `json:"size,omitempty"`
+	// TotalTime is the time taken by the request in seconds
+	TotalTime float64    `json:"totalTime,omitempty"`
+	FetchedAt *time.Time `json:"fetchedAt,omitempty"`
+	// FX and FY fix the position of the node, set by Layout for the layouts that aren't force-directed
+	FX *float64 `json:"fx,omitempty"`
+	FY *float64 `json:"fy,omitempty"`
+	// Pages is the number of pages of the host, in aggregated graphs (see Aggregate)
+	Pages int `json:"pages,omitempty"`
+	// Scores computed by the analyze command (if written back)
+	Scores map[string]float64 `json:"scores,omitempty"`
 }
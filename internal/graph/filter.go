@@ -0,0 +1,175 @@
+package graph
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/gustavooferreira/wcrawler"
+	"github.com/gustavooferreira/wcrawler/internal/analytics"
+)
+
+// Metrics nodes can be ranked by, to keep the top ones (see Filter.TopK).
+const (
+	RankByDegree   = "degree"
+	RankByPageRank = "pagerank"
+)
+
+// Status classes records can be filtered by (see Filter.StatusClasses), besides "2xx", "3xx", "4xx" and "5xx".
+const (
+	// StatusClassError represents requests that failed without a response
+	StatusClassError = "error"
+	// StatusClassUnvisited represents pages found but never requested (e.g. beyond the max depth)
+	StatusClassUnvisited = "unvisited"
+)
+
+// ErrorStatusClasses are the status classes of pages that couldn't be fetched successfully.
+var ErrorStatusClasses = []string{"4xx", "5xx", StatusClassError}
+
+// Filter restricts the nodes in the graph. A node must pass every filter set.
+// The zero value doesn't filter anything.
+type Filter struct {
+	// Hosts keeps the pages on these hosts or their subdomains
+	Hosts []string
+	// MaxDepth keeps the pages up to this depth (0 means no limit)
+	MaxDepth int
+	// StatusClasses keeps the pages with these status classes (e.g. "4xx" or "error")
+	StatusClasses []string
+	// URLPattern keeps the pages whose URL matches
+	URLPattern *regexp.Regexp
+
+	// EgoURL keeps the pages up to EgoHops hops away from this URL, following links both ways
+	EgoURL  string
+	EgoHops int
+
+	// TopK keeps the K pages left ranking highest by RankBy (RankByDegree or RankByPageRank).
+	// Degree and PageRank are computed on the whole graph.
+	TopK   int
+	RankBy string
+}
+
+// Apply returns the elements of the nodes passing the filter, and the links between them.
+// rm holds the records the elements were created from.
+func (f Filter) Apply(rm *wcrawler.RecordManager, elements Elements) (Elements, error) {
+	keep := func(node Node) bool {
+		r, ok := rm.Get(node.URL)
+		return ok && f.keepRecord(r)
+	}
+
+	if f.TopK > 0 && f.RankBy != "" && f.RankBy != RankByDegree && f.RankBy != RankByPageRank {
+		return Elements{}, fmt.Errorf("unsupported ranking %q (use '%s' or '%s')", f.RankBy, RankByDegree, RankByPageRank)
+	}
+
+	if f.EgoURL != "" {
+		ego, err := elements.Neighbourhood(f.EgoURL, f.EgoHops)
+		if err != nil {
+			return Elements{}, err
+		}
+		elements = ego
+	}
+
+	elements = elements.Subgraph(keep)
+
+	if f.TopK > 0 && f.TopK < len(elements.Nodes) {
+		scores := f.scores(rm, elements)
+
+		nodes := make([]Node, len(elements.Nodes))
+		copy(nodes, elements.Nodes)
+		sort.SliceStable(nodes, func(i, j int) bool { return scores[nodes[i].URL] > scores[nodes[j].URL] })
+
+		top := map[string]bool{}
+		for _, node := range nodes[:f.TopK] {
+			top[node.ID] = true
+		}
+
+		elements = elements.Subgraph(func(node Node) bool { return top[node.ID] })
+	}
+
+	return elements, nil
+}
+
+// keepRecord returns true if the record passes the host, depth, status and URL filters.
+func (f Filter) keepRecord(r wcrawler.Record) bool {
+	if len(f.Hosts) != 0 && !matchesHost(r.Host, f.Hosts) {
+		return false
+	}
+
+	if f.MaxDepth != 0 && r.Depth > f.MaxDepth {
+		return false
+	}
+
+	if len(f.StatusClasses) != 0 && !contains(f.StatusClasses, StatusClass(r)) {
+		return false
+	}
+
+	if f.URLPattern != nil && !f.URLPattern.MatchString(r.URL) {
+		return false
+	}
+
+	return true
+}
+
+// scores returns the score of every page by URL, according to RankBy.
+func (f Filter) scores(rm *wcrawler.RecordManager, elements Elements) map[string]float64 {
+	scores := map[string]float64{}
+
+	switch f.RankBy {
+	case RankByDegree, "":
+		// Degree in the whole graph, not only between the pages left
+		urls := map[int]string{}
+		for _, r := range rm.Records {
+			urls[r.Index] = r.URL
+			scores[r.URL] += float64(r.Edges.Count())
+		}
+		for _, r := range rm.Records {
+			for _, edge := range r.Edges.Dump() {
+				scores[urls[edge]]++
+			}
+		}
+	case RankByPageRank:
+		g := analytics.NewGraph(rm)
+		opts := analytics.DefaultOptions()
+		rank := g.PageRank(opts.Damping, opts.MaxIterations, opts.Tolerance)
+		for _, node := range elements.Nodes {
+			if pos, ok := g.Position(node.URL); ok {
+				scores[node.URL] = rank[pos]
+			}
+		}
+	}
+
+	return scores
+}
+
+// StatusClass returns the status class of the record: "2xx", "3xx", "4xx", "5xx", "error" or "unvisited".
+func StatusClass(r wcrawler.Record) string {
+	if r.StatusCode == 0 {
+		if r.ErrString != "" {
+			return StatusClassError
+		}
+		return StatusClassUnvisited
+	}
+
+	return fmt.Sprintf("%dxx", r.StatusCode/100)
+}
+
+// matchesHost returns true if the host is one of the domains or a subdomain of one of them.
+func matchesHost(host string, domains []string) bool {
+	for _, domain := range domains {
+		if host == domain || strings.HasSuffix(host, "."+domain) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,52 @@
+package graph_test
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/gustavooferreira/wcrawler/internal/graph"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFilterApply(t *testing.T) {
+	tests := map[string]struct {
+		filter        graph.Filter
+		expectedNodes []string
+		expectedLinks int
+		expectedErr   bool
+	}{
+		"no filter":         {filter: graph.Filter{}, expectedNodes: []string{"0", "1", "2", "3", "4"}, expectedLinks: 5},
+		"domain":            {filter: graph.Filter{Hosts: []string{"example.com"}}, expectedNodes: []string{"0", "1", "2", "3", "4"}, expectedLinks: 5},
+		"subdomain":         {filter: graph.Filter{Hosts: []string{"blog.example.com"}}, expectedNodes: []string{"3", "4"}, expectedLinks: 1},
+		"max depth":         {filter: graph.Filter{MaxDepth: 1}, expectedNodes: []string{"0", "1", "2"}, expectedLinks: 3},
+		"errors only":       {filter: graph.Filter{StatusClasses: graph.ErrorStatusClasses}, expectedNodes: []string{"2", "4"}, expectedLinks: 0},
+		"url pattern":       {filter: graph.Filter{URLPattern: regexp.MustCompile(`/(about|post)$`)}, expectedNodes: []string{"1", "4"}, expectedLinks: 0},
+		"ego network":       {filter: graph.Filter{EgoURL: "http://example.com/contact", EgoHops: 1}, expectedNodes: []string{"0", "2", "3"}, expectedLinks: 2},
+		"top by degree":     {filter: graph.Filter{TopK: 1, RankBy: graph.RankByDegree}, expectedNodes: []string{"0"}, expectedLinks: 0},
+		"top by pagerank":   {filter: graph.Filter{TopK: 2, RankBy: graph.RankByPageRank}, expectedNodes: []string{"0", "4"}, expectedLinks: 0},
+		"combined":          {filter: graph.Filter{Hosts: []string{"example.com"}, StatusClasses: graph.ErrorStatusClasses, TopK: 1}, expectedNodes: []string{"2"}, expectedLinks: 0},
+		"unknown ego URL":   {filter: graph.Filter{EgoURL: "http://example.com/unknown"}, expectedErr: true},
+		"unknown ranking":   {filter: graph.Filter{TopK: 1, RankBy: "size"}, expectedErr: true},
+		"top more than all": {filter: graph.Filter{TopK: 10}, expectedNodes: []string{"0", "1", "2", "3", "4"}, expectedLinks: 5},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			rm := newRecordManager()
+			elements, err := test.filter.Apply(rm, graph.NewElements(rm))
+			if test.expectedErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			ids := []string{}
+			for _, node := range elements.Nodes {
+				ids = append(ids, node.ID)
+			}
+			assert.Equal(t, test.expectedNodes, ids)
+			assert.Len(t, elements.Links, test.expectedLinks)
+		})
+	}
+}
@@ -0,0 +1,143 @@
+package graph
+
+import "math"
+
+// How nodes can be laid out in the graph page.
+const (
+	// Layout3D is the 3D force-directed layout
+	Layout3D = "3d"
+	// Layout2D is the 2D force-directed layout
+	Layout2D = "2d"
+	// LayoutTree places the pages in rows by crawl depth, under the page they were found on
+	LayoutTree = "tree"
+	// LayoutRadial places the pages in rings by crawl depth around the seed, next to the page they were found on
+	LayoutRadial = "radial"
+)
+
+// Layouts lists how nodes can be laid out.
+var Layouts = []string{Layout3D, Layout2D, LayoutTree, LayoutRadial}
+
+const (
+	// nodeSpacing is the distance between neighbouring leaves of the tree
+	nodeSpacing = 10.0
+	// levelSpacing is the distance between depth levels
+	levelSpacing = 60.0
+)
+
+// layoutMode returns the layout, or Layout3D if it's not one of Layouts.
+func layoutMode(layout string) string {
+	for _, mode := range Layouts {
+		if mode == layout {
+			return mode
+		}
+	}
+
+	return Layout3D
+}
+
+// Layout returns the elements with the nodes fixed in place for the layouts that aren't force-directed
+// (LayoutTree and LayoutRadial). Force-directed layouts are computed by the page, so the elements are returned as is.
+//
+// Both layouts are drawn from a spanning tree of the graph: the parent of a page is the first page one level up
+// linking to it (the page it was found on). Pages without a parent (the seeds and pages whose parent was filtered out)
+// are the roots of the tree.
+func Layout(elements Elements, layout string) Elements {
+	layout = layoutMode(layout)
+	if layout != LayoutTree && layout != LayoutRadial {
+		return elements
+	}
+
+	nodes := make([]Node, len(elements.Nodes))
+	copy(nodes, elements.Nodes)
+
+	roots, children := spanningTree(Elements{Nodes: nodes, Links: elements.Links})
+
+	// Leaves take consecutive slots, parents are centred above their children
+	slots := make([]float64, len(nodes))
+	leaves := 0
+	var place func(i int) float64
+	place = func(i int) float64 {
+		if len(children[i]) == 0 {
+			slots[i] = float64(leaves)
+			leaves++
+			return slots[i]
+		}
+
+		first := place(children[i][0])
+		last := first
+		for _, c := range children[i][1:] {
+			last = place(c)
+		}
+		slots[i] = (first + last) / 2
+		return slots[i]
+	}
+	for _, r := range roots {
+		place(r)
+	}
+
+	maxDepth := 1
+	for _, n := range nodes {
+		if n.Depth > maxDepth {
+			maxDepth = n.Depth
+		}
+	}
+
+	// Rings are spread out enough for the leaves on the outer ring to be nodeSpacing apart
+	ringSpacing := math.Max(levelSpacing, float64(leaves)*nodeSpacing/(2*math.Pi*float64(maxDepth)))
+
+	for i := range nodes {
+		var x, y float64
+		if layout == LayoutTree {
+			x = (slots[i] - float64(leaves-1)/2) * nodeSpacing
+			y = -float64(nodes[i].Depth) * levelSpacing
+		} else {
+			angle := 2 * math.Pi * slots[i] / float64(leaves)
+			radius := float64(nodes[i].Depth) * ringSpacing
+			x = radius * math.Cos(angle)
+			y = radius * math.Sin(angle)
+		}
+		nodes[i].FX = &x
+		nodes[i].FY = &y
+	}
+
+	return Elements{Nodes: nodes, Links: elements.Links}
+}
+
+// spanningTree returns the roots and the children of each node (by position in elements.Nodes) of the tree
+// of pages by crawl depth.
+func spanningTree(elements Elements) (roots []int, children [][]int) {
+	positions := make(map[string]int, len(elements.Nodes))
+	for i, n := range elements.Nodes {
+		positions[n.ID] = i
+	}
+
+	parents := make([]int, len(elements.Nodes))
+	for i := range parents {
+		parents[i] = -1
+	}
+
+	children = make([][]int, len(elements.Nodes))
+	for _, l := range elements.Links {
+		source, ok := positions[l.Source]
+		if !ok {
+			continue
+		}
+		target, ok := positions[l.Target]
+		if !ok || parents[target] != -1 {
+			continue
+		}
+
+		if elements.Nodes[target].Depth == elements.Nodes[source].Depth+1 {
+			parents[target] = source
+			children[source] = append(children[source], target)
+		}
+	}
+
+	for i, p := range parents {
+		if p == -1 {
+			roots = append(roots, i)
+		}
+	}
+
+	return roots, children
+}
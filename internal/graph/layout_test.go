@@ -0,0 +1,67 @@
+package graph_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/gustavooferreira/wcrawler/internal/graph"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLayout(t *testing.T) {
+	elements := graph.NewElements(newRecordManager())
+
+	t.Run("force-directed", func(t *testing.T) {
+		for _, layout := range []string{graph.Layout3D, graph.Layout2D, "unknown"} {
+			for _, n := range graph.Layout(elements, layout).Nodes {
+				assert.Nil(t, n.FX, layout)
+				assert.Nil(t, n.FY, layout)
+			}
+		}
+	})
+
+	t.Run("tree", func(t *testing.T) {
+		nodes := positions(t, graph.Layout(elements, graph.LayoutTree))
+
+		// One row per depth level
+		assert.Equal(t, 0.0, nodes["http://example.com/"][1])
+		assert.Equal(t, nodes["http://example.com/about"][1], nodes["http://example.com/contact"][1])
+		assert.Less(t, nodes["http://example.com/about"][1], 0.0)
+		assert.Less(t, nodes["http://blog.example.com/post"][1], nodes["http://blog.example.com/"][1])
+
+		// Parents are centred above their children
+		assert.Equal(t, (nodes["http://example.com/about"][0]+nodes["http://example.com/contact"][0])/2,
+			nodes["http://example.com/"][0])
+		assert.Equal(t, nodes["http://example.com/contact"][0], nodes["http://blog.example.com/"][0])
+	})
+
+	t.Run("radial", func(t *testing.T) {
+		nodes := positions(t, graph.Layout(elements, graph.LayoutRadial))
+
+		// The seed is in the centre, pages at the same depth on the same ring
+		assert.Equal(t, [2]float64{0, 0}, nodes["http://example.com/"])
+		about := math.Hypot(nodes["http://example.com/about"][0], nodes["http://example.com/about"][1])
+		contact := math.Hypot(nodes["http://example.com/contact"][0], nodes["http://example.com/contact"][1])
+		post := math.Hypot(nodes["http://blog.example.com/post"][0], nodes["http://blog.example.com/post"][1])
+		assert.InDelta(t, about, contact, 1e-9)
+		assert.InDelta(t, 3*about, post, 1e-9)
+	})
+
+	// The original elements aren't changed
+	for _, n := range elements.Nodes {
+		assert.Nil(t, n.FX)
+	}
+}
+
+// positions returns the fixed positions of the nodes by URL.
+func positions(t *testing.T, elements graph.Elements) map[string][2]float64 {
+	result := map[string][2]float64{}
+	for _, n := range elements.Nodes {
+		require.NotNil(t, n.FX, n.URL)
+		require.NotNil(t, n.FY, n.URL)
+		result[n.URL] = [2]float64{*n.FX, *n.FY}
+	}
+
+	return result
+}
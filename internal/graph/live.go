@@ -0,0 +1,248 @@
+package graph
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+
+	"github.com/gustavooferreira/wcrawler"
+	"github.com/gustavooferreira/wcrawler/internal/httpjson"
+)
+
+// liveEventsBufferSize is the number of events buffered per events stream.
+// Slow clients are disconnected, to reconnect and start over from a snapshot.
+const liveEventsBufferSize = 1000
+
+// liveEvent represents a change to the graph sent to the events streams.
+type liveEvent struct {
+	name string
+	data interface{}
+}
+
+// LiveServer serves the graph page of a running crawl, which grows as pages are found and is recoloured as they're
+// requested. It implements wcrawler.GraphListener, to get the changes from the crawler, and http.Handler:
+//
+//	GET /             the graph page
+//	GET /api/graph    the nodes and links found so far
+//	GET /api/events   server-sent events stream of the changes: a 'snapshot' event with the nodes and links found
+//	                  so far, then 'node' (new or updated node) and 'link' events, ending with a 'finished' event
+type LiveServer struct {
+	opts ViewerOptions
+	mux  *http.ServeMux
+
+	mu       sync.Mutex
+	elements Elements
+	// positions maps record indexes to positions in elements.Nodes
+	positions map[int]int
+	links     map[[2]int]struct{}
+	// subscribers to the changes
+	subscribers map[chan liveEvent]struct{}
+	finished    bool
+}
+
+// NewLiveServer returns a new LiveServer. Only the colour mode and whether to use the CDN are taken from the options.
+// It returns ErrLibraryMissing if the library isn't embedded and the options don't set UseCDN.
+func NewLiveServer(opts ViewerOptions) (*LiveServer, error) {
+	if err := checkLibrary(opts.UseCDN); err != nil {
+		return nil, err
+	}
+
+	s := LiveServer{
+		opts:        opts,
+		mux:         http.NewServeMux(),
+		elements:    Elements{Nodes: []Node{}, Links: []Link{}},
+		positions:   map[int]int{},
+		links:       map[[2]int]struct{}{},
+		subscribers: map[chan liveEvent]struct{}{},
+	}
+
+	s.mux.HandleFunc("/", s.handlePage)
+	s.mux.HandleFunc(libraryPath, handleLibrary)
+	s.mux.HandleFunc("/api/graph", s.handleGraph)
+	s.mux.HandleFunc("/api/events", s.handleEvents)
+
+	return &s, nil
+}
+
+// UpdateRecord adds the node of the record, or updates it if it's already in the graph.
+func (s *LiveServer) UpdateRecord(record wcrawler.Record) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Degrees are counted as links are added
+	node := newNode(record, 0)
+	node.LinksCount = 0
+
+	if pos, ok := s.positions[record.Index]; ok {
+		node.LinksCount = s.elements.Nodes[pos].LinksCount
+		node.InDegree = s.elements.Nodes[pos].InDegree
+		s.elements.Nodes[pos] = node
+	} else {
+		s.positions[record.Index] = len(s.elements.Nodes)
+		s.elements.Nodes = append(s.elements.Nodes, node)
+	}
+
+	s.broadcast(liveEvent{name: "node", data: node})
+}
+
+// AddLink adds the link between the nodes of the records, if it isn't in the graph already.
+func (s *LiveServer) AddLink(from int, to int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.links[[2]int{from, to}]; ok {
+		return
+	}
+	fromPos, ok := s.positions[from]
+	if !ok {
+		return
+	}
+	toPos, ok := s.positions[to]
+	if !ok {
+		return
+	}
+
+	s.links[[2]int{from, to}] = struct{}{}
+	link := Link{Source: strconv.Itoa(from), Target: strconv.Itoa(to)}
+	s.elements.Links = append(s.elements.Links, link)
+	s.elements.Nodes[fromPos].LinksCount++
+	s.elements.Nodes[toPos].InDegree++
+
+	s.broadcast(liveEvent{name: "link", data: link})
+	s.broadcast(liveEvent{name: "node", data: s.elements.Nodes[fromPos]})
+	if toPos != fromPos {
+		s.broadcast(liveEvent{name: "node", data: s.elements.Nodes[toPos]})
+	}
+}
+
+// Finished sends the 'finished' event and closes the events streams.
+func (s *LiveServer) Finished() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.finished = true
+	s.broadcast(liveEvent{name: "finished", data: struct{}{}})
+	for events := range s.subscribers {
+		close(events)
+		delete(s.subscribers, events)
+	}
+}
+
+// Elements returns the nodes and links found so far.
+func (s *LiveServer) Elements() Elements {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.snapshot()
+}
+
+// ServeHTTP dispatches the request to the endpoint for its path.
+func (s *LiveServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		httpjson.WriteError(w, http.StatusMethodNotAllowed, fmt.Errorf("method %s not allowed", r.Method))
+		return
+	}
+
+	s.mux.ServeHTTP(w, r)
+}
+
+func (s *LiveServer) handlePage(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/" {
+		http.NotFound(w, r)
+		return
+	}
+
+	vars := servedPageVars(ViewerOptions{UseCDN: s.opts.UseCDN, ColorBy: s.opts.ColorBy})
+	vars.Live = true
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	executeTemplate(w, vars)
+}
+
+func (s *LiveServer) handleGraph(w http.ResponseWriter, r *http.Request) {
+	httpjson.Write(w, http.StatusOK, s.Elements())
+}
+
+func (s *LiveServer) handleEvents(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		httpjson.WriteError(w, http.StatusInternalServerError, fmt.Errorf("streaming not supported"))
+		return
+	}
+
+	snapshot, events := s.subscribe()
+	defer s.unsubscribe(events)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+
+	httpjson.WriteEvent(w, "snapshot", snapshot)
+	flusher.Flush()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case event, ok := <-events:
+			if !ok {
+				// Either the crawl finished (after the 'finished' event) or the client didn't keep up
+				return
+			}
+
+			httpjson.WriteEvent(w, event.name, event.data)
+			flusher.Flush()
+		}
+	}
+}
+
+// subscribe returns the nodes and links found so far, and a channel receiving the changes from then on.
+// If the crawl is over, the channel only gets the 'finished' event.
+func (s *LiveServer) subscribe() (Elements, chan liveEvent) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	events := make(chan liveEvent, liveEventsBufferSize)
+	if s.finished {
+		events <- liveEvent{name: "finished", data: struct{}{}}
+		close(events)
+		return s.snapshot(), events
+	}
+
+	s.subscribers[events] = struct{}{}
+	return s.snapshot(), events
+}
+
+// unsubscribe stops sending changes to the channel.
+func (s *LiveServer) unsubscribe(events chan liveEvent) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.subscribers, events)
+}
+
+// broadcast sends the event to the subscribers, dropping the ones that aren't keeping up.
+// It must be called holding the lock.
+func (s *LiveServer) broadcast(event liveEvent) {
+	for events := range s.subscribers {
+		select {
+		case events <- event:
+		default:
+			close(events)
+			delete(s.subscribers, events)
+		}
+	}
+}
+
+// snapshot returns a copy of the nodes and links. It must be called holding the lock.
+func (s *LiveServer) snapshot() Elements {
+	elements := Elements{
+		Nodes: make([]Node, len(s.elements.Nodes)),
+		Links: make([]Link, len(s.elements.Links)),
+	}
+	copy(elements.Nodes, s.elements.Nodes)
+	copy(elements.Links, s.elements.Links)
+
+	return elements
+}
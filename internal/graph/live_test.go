@@ -0,0 +1,116 @@
+package graph_test
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gustavooferreira/wcrawler"
+	"github.com/gustavooferreira/wcrawler/internal/graph"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLiveServerElements(t *testing.T) {
+	s, err := graph.NewLiveServer(graph.ViewerOptions{UseCDN: true})
+	require.NoError(t, err)
+
+	s.UpdateRecord(wcrawler.Record{Index: 0, URL: "http://example.com/", Host: "example.com"})
+	s.UpdateRecord(wcrawler.Record{Index: 1, URL: "http://example.com/about", Host: "example.com", Depth: 1})
+	s.AddLink(0, 1)
+	s.AddLink(0, 1)
+	s.AddLink(1, 0)
+	// Unknown nodes
+	s.AddLink(0, 2)
+
+	// Requested, keeping the degrees
+	s.UpdateRecord(wcrawler.Record{Index: 1, URL: "http://example.com/about", Host: "example.com", Depth: 1, StatusCode: 404})
+
+	elements := s.Elements()
+	assert.Equal(t, []graph.Link{{Source: "0", Target: "1"}, {Source: "1", Target: "0"}}, elements.Links)
+	require.Len(t, elements.Nodes, 2)
+
+	about := elements.Nodes[1]
+	assert.Equal(t, "http://example.com/about", about.URL)
+	assert.Equal(t, "4xx", about.StatusClass)
+	assert.Equal(t, 1, about.InDegree)
+	assert.Equal(t, 1, about.LinksCount)
+}
+
+func TestLiveServerEvents(t *testing.T) {
+	s, err := graph.NewLiveServer(graph.ViewerOptions{UseCDN: true})
+	require.NoError(t, err)
+	s.UpdateRecord(wcrawler.Record{Index: 0, URL: "http://example.com/", Host: "example.com"})
+
+	server := httptest.NewServer(s)
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/api/events")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+	reader := bufio.NewReader(resp.Body)
+	readEvent := func(value interface{}) string {
+		name, err := reader.ReadString('\n')
+		require.NoError(t, err)
+		data, err := reader.ReadString('\n')
+		require.NoError(t, err)
+		_, err = reader.ReadString('\n')
+		require.NoError(t, err)
+
+		require.NoError(t, json.Unmarshal([]byte(strings.TrimPrefix(data, "data: ")), value))
+		return strings.TrimSpace(strings.TrimPrefix(name, "event: "))
+	}
+
+	var snapshot graph.Elements
+	assert.Equal(t, "snapshot", readEvent(&snapshot))
+	require.Len(t, snapshot.Nodes, 1)
+	assert.Equal(t, "http://example.com/", snapshot.Nodes[0].URL)
+
+	s.UpdateRecord(wcrawler.Record{Index: 1, URL: "http://example.com/about", Host: "example.com", Depth: 1})
+	s.AddLink(0, 1)
+
+	var node graph.Node
+	assert.Equal(t, "node", readEvent(&node))
+	assert.Equal(t, "1", node.ID)
+
+	var link graph.Link
+	assert.Equal(t, "link", readEvent(&link))
+	assert.Equal(t, graph.Link{Source: "0", Target: "1"}, link)
+
+	// Both ends of the link, with the new degrees
+	assert.Equal(t, "node", readEvent(&node))
+	assert.Equal(t, 1, node.LinksCount)
+	assert.Equal(t, "node", readEvent(&node))
+	assert.Equal(t, 1, node.InDegree)
+
+	s.Finished()
+
+	var finished struct{}
+	assert.Equal(t, "finished", readEvent(&finished))
+}
+
+func TestLiveServerPage(t *testing.T) {
+	s, err := graph.NewLiveServer(graph.ViewerOptions{UseCDN: true})
+	require.NoError(t, err)
+
+	w := httptest.NewRecorder()
+	s.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), graph.LibraryCDN)
+	assert.Contains(t, w.Body.String(), "new EventSource('/api/events')")
+}
+
+func TestLiveServerMissingLibrary(t *testing.T) {
+	if graph.LibraryEmbedded() {
+		t.Skip("the library is vendored in internal/graph/assets")
+	}
+
+	_, err := graph.NewLiveServer(graph.ViewerOptions{})
+	assert.ErrorIs(t, err, graph.ErrLibraryMissing)
+}
@@ -0,0 +1,458 @@
+package graph
+
+import (
+	"bufio"
+	"encoding/xml"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"io"
+	"math"
+	"sort"
+	"strings"
+)
+
+// Formats the graph can be written in.
+const (
+	FormatHTML = "html"
+	FormatSVG  = "svg"
+	FormatPNG  = "png"
+	// FormatTree is an HTML page with the pages organised by URL path (see SiteTree)
+	FormatTree = "tree"
+	// FormatJSON is the nodes and links as JSON (see Elements), to export the graph to other tools
+	FormatJSON = "json"
+)
+
+// Formats lists the formats the graph can be written in.
+var Formats = []string{FormatHTML, FormatSVG, FormatPNG, FormatTree, FormatJSON}
+
+const (
+	// imageWidth and imageHeight are the size of rendered images in pixels
+	imageWidth  = 1600
+	imageHeight = 1200
+	// linkWidth is the width of the links in SVG images, wider for weighted links
+	linkWidth = 0.8
+	// imageMargin is the space left around the graph in pixels
+	imageMargin = 40
+	// maxImageScale limits how much small graphs are scaled up to fill the image
+	maxImageScale = 5.0
+
+	// maxForceNodes is the max number of nodes laid out by the force-directed layout in images, as every iteration
+	// goes through every pair of nodes. Larger graphs keep the radial layout it starts from.
+	maxForceNodes = 2000
+	// forceIterations is the number of iterations of the force-directed layout
+	forceIterations = 150
+	// forceDistance is the ideal distance between linked nodes in the force-directed layout
+	forceDistance = 30.0
+	// forceGravity pulls the nodes towards the centre, so disconnected parts don't drift away
+	forceGravity = 0.01
+)
+
+var (
+	backgroundColor = color.RGBA{0xff, 0xff, 0xff, 0xff}
+	linkColor       = color.RGBA{0x99, 0x99, 0x99, 0x66}
+	labelColor      = color.RGBA{0x22, 0x22, 0x22, 0xff}
+
+	// statusColors are the colours of the status classes, the same as in the graph page
+	statusColors = map[string]color.RGBA{
+		"2xx":                {0x4c, 0xaf, 0x50, 0xff},
+		"3xx":                {0x21, 0x96, 0xf3, 0xff},
+		"4xx":                {0xf4, 0x43, 0x36, 0xff},
+		"5xx":                {0xb7, 0x1c, 0x1c, 0xff},
+		StatusClassError:     {0xff, 0x98, 0x00, 0xff},
+		StatusClassUnvisited: {0x9e, 0x9e, 0x9e, 0xff},
+	}
+	errorColor   = color.RGBA{0xf4, 0x43, 0x36, 0xff}
+	noErrorColor = color.RGBA{0x60, 0x7d, 0x8b, 0xff}
+)
+
+// point represents a position in the image.
+type point struct {
+	X, Y float64
+}
+
+// drawing represents the graph laid out in an image.
+type drawing struct {
+	elements Elements
+	points   []point
+	radii    []float64
+	colors   []color.RGBA
+	// links are the positions of the source and target nodes of each link, widths the width of each link
+	links  [][2]int
+	widths []float64
+}
+
+// RenderSVG writes an SVG image of the graph, laid out and coloured as set in the options (the filter isn't applied).
+// The opts.Labels nodes with the highest in-degree are labelled with their URL.
+func RenderSVG(elements Elements, w io.Writer, opts ViewerOptions) error {
+	d := newDrawing(elements, opts)
+	bw := bufio.NewWriter(w)
+
+	fmt.Fprintf(bw, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d">`+"\n",
+		imageWidth, imageHeight, imageWidth, imageHeight)
+	fmt.Fprintf(bw, `<rect width="100%%" height="100%%" fill="%s"/>`+"\n", hexColor(backgroundColor))
+
+	fmt.Fprintf(bw, `<g stroke="%s" stroke-opacity="%.2f" stroke-width="%.1f">`+"\n",
+		hexColor(linkColor), float64(linkColor.A)/0xff, linkWidth)
+	for i, l := range d.links {
+		from, to := d.points[l[0]], d.points[l[1]]
+		if d.widths[i] == linkWidth {
+			fmt.Fprintf(bw, `<line x1="%.1f" y1="%.1f" x2="%.1f" y2="%.1f"/>`+"\n", from.X, from.Y, to.X, to.Y)
+		} else {
+			fmt.Fprintf(bw, `<line x1="%.1f" y1="%.1f" x2="%.1f" y2="%.1f" stroke-width="%.1f"/>`+"\n",
+				from.X, from.Y, to.X, to.Y, d.widths[i])
+		}
+	}
+	fmt.Fprintln(bw, `</g>`)
+
+	fmt.Fprintf(bw, `<g stroke="%s" stroke-width="0.5">`+"\n", hexColor(backgroundColor))
+	for i, n := range d.elements.Nodes {
+		fmt.Fprintf(bw, `<circle cx="%.1f" cy="%.1f" r="%.1f" fill="%s"><title>%s</title></circle>`+"\n",
+			d.points[i].X, d.points[i].Y, d.radii[i], hexColor(d.colors[i]), escapeXML(n.URL))
+	}
+	fmt.Fprintln(bw, `</g>`)
+
+	fmt.Fprintf(bw, `<g font-family="sans-serif" font-size="11" fill="%s">`+"\n", hexColor(labelColor))
+	for _, i := range d.topNodes(opts.Labels) {
+		fmt.Fprintf(bw, `<text x="%.1f" y="%.1f">%s</text>`+"\n",
+			d.points[i].X+d.radii[i]+2, d.points[i].Y+4, escapeXML(d.elements.Nodes[i].URL))
+	}
+	fmt.Fprintln(bw, `</g>`)
+	fmt.Fprintln(bw, `</svg>`)
+
+	return bw.Flush()
+}
+
+// RenderPNG writes a PNG image of the graph, laid out and coloured as set in the options (the filter isn't applied).
+// Labels aren't drawn, as there's no font to draw them with.
+func RenderPNG(elements Elements, w io.Writer, opts ViewerOptions) error {
+	d := newDrawing(elements, opts)
+
+	img := image.NewRGBA(image.Rect(0, 0, imageWidth, imageHeight))
+	draw.Draw(img, img.Bounds(), image.NewUniform(backgroundColor), image.Point{}, draw.Src)
+
+	for i, l := range d.links {
+		drawLine(img, d.points[l[0]], d.points[l[1]], int(math.Max(1, math.Round(d.widths[i]))), linkColor)
+	}
+	for i := range d.elements.Nodes {
+		drawCircle(img, d.points[i], d.radii[i], d.colors[i])
+	}
+
+	return png.Encode(w, img)
+}
+
+// newDrawing lays out the graph and fits it in the image.
+func newDrawing(elements Elements, opts ViewerOptions) drawing {
+	d := drawing{elements: elements, points: imageLayout(elements, opts.Layout)}
+	fitPoints(d.points)
+
+	positions := make(map[string]int, len(elements.Nodes))
+	for i, n := range elements.Nodes {
+		positions[n.ID] = i
+	}
+	for _, l := range elements.Links {
+		source, ok := positions[l.Source]
+		if !ok {
+			continue
+		}
+		target, ok := positions[l.Target]
+		if !ok {
+			continue
+		}
+		d.links = append(d.links, [2]int{source, target})
+		d.widths = append(d.widths, weightedLinkWidth(l.Weight))
+	}
+
+	colorBy := colorMode(opts.ColorBy)
+	palette := map[string]color.RGBA{}
+	maxDepth := 1
+	for _, n := range elements.Nodes {
+		if n.Depth > maxDepth {
+			maxDepth = n.Depth
+		}
+	}
+
+	for _, n := range elements.Nodes {
+		// Same as the size by in-degree in the graph page
+		d.radii = append(d.radii, 3*math.Cbrt(float64(1+n.InDegree)))
+		d.colors = append(d.colors, nodeColor(n, colorBy, maxDepth, palette))
+	}
+
+	return d
+}
+
+// topNodes returns the positions of the n nodes with the highest in-degree.
+func (d drawing) topNodes(n int) []int {
+	top := make([]int, len(d.elements.Nodes))
+	for i := range top {
+		top[i] = i
+	}
+	sort.SliceStable(top, func(i, j int) bool {
+		return d.elements.Nodes[top[i]].InDegree > d.elements.Nodes[top[j]].InDegree
+	})
+
+	if n < len(top) {
+		top = top[:n]
+	}
+
+	return top
+}
+
+// nodeColor returns the colour of the node, as the graph page colours it.
+// Values without a colour of their own (hosts and content types) take the next colour of the palette.
+func nodeColor(n Node, colorBy string, maxDepth int, palette map[string]color.RGBA) color.RGBA {
+	switch colorBy {
+	case ColorByStatus:
+		if c, ok := statusColors[n.StatusClass]; ok {
+			return c
+		}
+		return statusColors[StatusClassUnvisited]
+	case ColorByDepth:
+		return hslColor(240*(1-float64(n.Depth)/float64(maxDepth)), 0.8, 0.55)
+	case ColorByError:
+		if n.Error != "" || n.StatusClass == "4xx" || n.StatusClass == "5xx" {
+			return errorColor
+		}
+		return noErrorColor
+	}
+
+	value := n.Domain
+	if colorBy == ColorByContentType {
+		value = n.ContentType
+	}
+
+	c, ok := palette[value]
+	if !ok {
+		c = hslColor(math.Mod(float64(len(palette))*137.5, 360), 0.7, 0.55)
+		palette[value] = c
+	}
+
+	return c
+}
+
+// imageLayout returns the positions of the nodes. The 3d and 2d layouts are both drawn with a 2D force-directed layout.
+func imageLayout(elements Elements, layout string) []point {
+	switch layoutMode(layout) {
+	case LayoutTree, LayoutRadial:
+		return fixedPoints(Layout(elements, layout))
+	}
+
+	return forceLayout(elements)
+}
+
+// fixedPoints returns the positions set by Layout. The y axis points down in images.
+func fixedPoints(elements Elements) []point {
+	points := make([]point, len(elements.Nodes))
+	for i, n := range elements.Nodes {
+		if n.FX != nil && n.FY != nil {
+			points[i] = point{X: *n.FX, Y: -*n.FY}
+		}
+	}
+
+	return points
+}
+
+// forceLayout returns the positions of the nodes laid out by a force-directed algorithm (Fruchterman-Reingold),
+// starting from the radial layout, so the result is always the same.
+func forceLayout(elements Elements) []point {
+	points := fixedPoints(Layout(elements, LayoutRadial))
+	if len(points) < 2 || len(points) > maxForceNodes {
+		return points
+	}
+
+	positions := make(map[string]int, len(elements.Nodes))
+	for i, n := range elements.Nodes {
+		positions[n.ID] = i
+	}
+
+	temperature := 10 * forceDistance
+	cooling := temperature / forceIterations
+	disp := make([]point, len(points))
+
+	for it := 0; it < forceIterations; it++ {
+		for i := range disp {
+			disp[i] = point{X: -points[i].X * forceGravity, Y: -points[i].Y * forceGravity}
+		}
+
+		// Every pair of nodes pushes each other away
+		for i := range points {
+			for j := i + 1; j < len(points); j++ {
+				dx, dy, dist := distance(points[i], points[j])
+				f := forceDistance * forceDistance / dist
+				disp[i].X += dx / dist * f
+				disp[i].Y += dy / dist * f
+				disp[j].X -= dx / dist * f
+				disp[j].Y -= dy / dist * f
+			}
+		}
+
+		// Links pull the nodes together
+		for _, l := range elements.Links {
+			source, ok := positions[l.Source]
+			if !ok {
+				continue
+			}
+			target, ok := positions[l.Target]
+			if !ok || source == target {
+				continue
+			}
+			dx, dy, dist := distance(points[source], points[target])
+			f := dist * dist / forceDistance
+			disp[source].X -= dx / dist * f
+			disp[source].Y -= dy / dist * f
+			disp[target].X += dx / dist * f
+			disp[target].Y += dy / dist * f
+		}
+
+		// Nodes move at most as far as the temperature, which cools down at every iteration
+		for i := range points {
+			length := math.Hypot(disp[i].X, disp[i].Y)
+			if length == 0 {
+				continue
+			}
+			step := math.Min(length, temperature)
+			points[i].X += disp[i].X / length * step
+			points[i].Y += disp[i].Y / length * step
+		}
+		temperature -= cooling
+	}
+
+	return points
+}
+
+// distance returns the vector from b to a and its length, which is never zero
+// (nodes in the same place are pushed apart horizontally).
+func distance(a, b point) (dx, dy, dist float64) {
+	dx, dy = a.X-b.X, a.Y-b.Y
+	dist = math.Hypot(dx, dy)
+	if dist < 0.01 {
+		return 0.01, 0, 0.01
+	}
+
+	return dx, dy, dist
+}
+
+// fitPoints scales and moves the points to fit in the image, keeping the aspect ratio.
+func fitPoints(points []point) {
+	if len(points) == 0 {
+		return
+	}
+
+	minX, minY, maxX, maxY := points[0].X, points[0].Y, points[0].X, points[0].Y
+	for _, p := range points {
+		minX, maxX = math.Min(minX, p.X), math.Max(maxX, p.X)
+		minY, maxY = math.Min(minY, p.Y), math.Max(maxY, p.Y)
+	}
+
+	width, height := float64(imageWidth-2*imageMargin), float64(imageHeight-2*imageMargin)
+	scale := math.Inf(1)
+	if maxX > minX {
+		scale = width / (maxX - minX)
+	}
+	if maxY > minY {
+		scale = math.Min(scale, height/(maxY-minY))
+	}
+	scale = math.Min(scale, maxImageScale)
+
+	// Centred in the image
+	offsetX := imageMargin + (width-(maxX-minX)*scale)/2
+	offsetY := imageMargin + (height-(maxY-minY)*scale)/2
+	for i := range points {
+		points[i].X = offsetX + (points[i].X-minX)*scale
+		points[i].Y = offsetY + (points[i].Y-minY)*scale
+	}
+}
+
+// weightedLinkWidth returns the width of a link, growing with the log of its weight in aggregated graphs.
+func weightedLinkWidth(weight int) float64 {
+	if weight <= 1 {
+		return linkWidth
+	}
+
+	return linkWidth * (1 + math.Log2(float64(weight)))
+}
+
+// drawLine draws a line between the points, the width in pixels, blending the colour with the image.
+// Wider lines are drawn as parallel lines a pixel apart.
+func drawLine(img *image.RGBA, from, to point, width int, c color.RGBA) {
+	dx, dy, length := distance(to, from)
+	// Unit vector perpendicular to the line
+	nx, ny := -dy/length, dx/length
+
+	steps := int(math.Max(math.Abs(dx), math.Abs(dy)))
+	for w := 0; w < width; w++ {
+		offset := float64(w) - float64(width-1)/2
+		for s := 0; s <= steps; s++ {
+			t := 0.0
+			if steps != 0 {
+				t = float64(s) / float64(steps)
+			}
+			x := from.X + dx*t + nx*offset
+			y := from.Y + dy*t + ny*offset
+			blend(img, int(math.Round(x)), int(math.Round(y)), c)
+		}
+	}
+}
+
+// drawCircle draws a filled circle.
+func drawCircle(img *image.RGBA, center point, radius float64, c color.RGBA) {
+	for y := int(center.Y - radius); y <= int(center.Y+radius)+1; y++ {
+		for x := int(center.X - radius); x <= int(center.X+radius)+1; x++ {
+			if math.Hypot(float64(x)-center.X, float64(y)-center.Y) <= radius {
+				blend(img, x, y, c)
+			}
+		}
+	}
+}
+
+// blend draws the colour over the pixel, according to its alpha.
+func blend(img *image.RGBA, x, y int, c color.RGBA) {
+	if !(image.Point{X: x, Y: y}.In(img.Bounds())) {
+		return
+	}
+
+	bg := img.RGBAAt(x, y)
+	a := uint32(c.A)
+	mix := func(fg, bg uint8) uint8 { return uint8((uint32(fg)*a + uint32(bg)*(0xff-a)) / 0xff) }
+	img.SetRGBA(x, y, color.RGBA{mix(c.R, bg.R), mix(c.G, bg.G), mix(c.B, bg.B), 0xff})
+}
+
+// hslColor converts a colour from HSL (hue in degrees, saturation and lightness between 0 and 1) to RGB.
+func hslColor(h, s, l float64) color.RGBA {
+	chroma := (1 - math.Abs(2*l-1)) * s
+	x := chroma * (1 - math.Abs(math.Mod(h/60, 2)-1))
+	m := l - chroma/2
+
+	var r, g, b float64
+	switch {
+	case h < 60:
+		r, g, b = chroma, x, 0
+	case h < 120:
+		r, g, b = x, chroma, 0
+	case h < 180:
+		r, g, b = 0, chroma, x
+	case h < 240:
+		r, g, b = 0, x, chroma
+	case h < 300:
+		r, g, b = x, 0, chroma
+	default:
+		r, g, b = chroma, 0, x
+	}
+
+	channel := func(v float64) uint8 { return uint8(math.Round((v + m) * 0xff)) }
+	return color.RGBA{channel(r), channel(g), channel(b), 0xff}
+}
+
+// hexColor returns the colour as "#rrggbb" (without alpha).
+func hexColor(c color.RGBA) string {
+	return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+}
+
+// escapeXML escapes the text to be written in an XML document.
+func escapeXML(s string) string {
+	var sb strings.Builder
+	xml.EscapeText(&sb, []byte(s))
+	return sb.String()
+}
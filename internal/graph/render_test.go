@@ -0,0 +1,98 @@
+package graph_test
+
+import (
+	"bytes"
+	"encoding/xml"
+	"image/png"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/gustavooferreira/wcrawler/internal/graph"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenderSVG(t *testing.T) {
+	elements := graph.NewElements(newRecordManager())
+
+	for _, layout := range graph.Layouts {
+		t.Run(layout, func(t *testing.T) {
+			var buf bytes.Buffer
+			err := graph.RenderSVG(elements, &buf, graph.ViewerOptions{Layout: layout, ColorBy: graph.ColorByStatus, Labels: 2})
+			require.NoError(t, err)
+
+			elementsCount := map[string]int{}
+			var labels []string
+			decoder := xml.NewDecoder(&buf)
+			for {
+				token, err := decoder.Token()
+				if err == io.EOF {
+					break
+				}
+				require.NoError(t, err)
+
+				if start, ok := token.(xml.StartElement); ok {
+					elementsCount[start.Name.Local]++
+					if start.Name.Local == "text" {
+						var text string
+						require.NoError(t, decoder.DecodeElement(&text, &start))
+						labels = append(labels, text)
+					}
+				}
+			}
+
+			assert.Equal(t, 5, elementsCount["circle"])
+			assert.Equal(t, len(elements.Links), elementsCount["line"])
+			// The most linked to page first
+			require.Len(t, labels, 2)
+			assert.Equal(t, "http://example.com/", labels[0])
+		})
+	}
+
+	t.Run("status colours", func(t *testing.T) {
+		var buf bytes.Buffer
+		err := graph.RenderSVG(elements, &buf, graph.ViewerOptions{ColorBy: graph.ColorByStatus})
+		require.NoError(t, err)
+
+		assert.Equal(t, 3, strings.Count(buf.String(), `fill="#4caf50"`))
+		assert.Equal(t, 1, strings.Count(buf.String(), `fill="#f44336"`))
+		assert.Equal(t, 1, strings.Count(buf.String(), `fill="#b71c1c"`))
+	})
+	t.Run("weighted links", func(t *testing.T) {
+		elements := graph.Elements{
+			Nodes: []graph.Node{{ID: "a.com"}, {ID: "b.com"}, {ID: "c.com"}},
+			Links: []graph.Link{{Source: "a.com", Target: "b.com", Weight: 4}, {Source: "a.com", Target: "c.com", Weight: 1}},
+		}
+
+		var buf bytes.Buffer
+		require.NoError(t, graph.RenderSVG(elements, &buf, graph.ViewerOptions{}))
+
+		// Only the links with a weight over 1 are wider than the default
+		assert.Equal(t, 1, strings.Count(buf.String(), `stroke-width="2.4"/>`))
+	})
+}
+
+func TestRenderPNG(t *testing.T) {
+	var buf bytes.Buffer
+	err := graph.RenderPNG(graph.NewElements(newRecordManager()), &buf, graph.ViewerOptions{Layout: graph.LayoutTree})
+	require.NoError(t, err)
+
+	img, err := png.Decode(&buf)
+	require.NoError(t, err)
+	assert.Equal(t, 1600, img.Bounds().Dx())
+	assert.Equal(t, 1200, img.Bounds().Dy())
+
+	// Something other than the background was drawn
+	bounds := img.Bounds()
+	drawn := false
+	for y := bounds.Min.Y; y < bounds.Max.Y && !drawn; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			if r, g, b, _ := img.At(x, y).RGBA(); r != 0xffff || g != 0xffff || b != 0xffff {
+				drawn = true
+				break
+			}
+		}
+	}
+	assert.True(t, drawn)
+}
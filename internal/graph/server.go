@@ -0,0 +1,205 @@
+package graph
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gustavooferreira/wcrawler"
+	"github.com/gustavooferreira/wcrawler/internal/httpjson"
+)
+
+const (
+	// libraryPath is where the Server serves the embedded 3d-force-graph library
+	libraryPath = "/assets/3d-force-graph.min.js"
+	// defaultPageSize is the number of nodes or links returned per page when not set in the request
+	defaultPageSize = 1000
+	// maxPageSize is the max number of nodes or links returned per page
+	maxPageSize = 10000
+	// defaultHops is the number of hops around a URL returned by a subgraph query when not set in the request
+	defaultHops = 1
+)
+
+// NodesPage represents a page of nodes returned by the Server.
+type NodesPage struct {
+	Total  int    `json:"total"`
+	Offset int    `json:"offset"`
+	Nodes  []Node `json:"nodes"`
+}
+
+// LinksPage represents a page of links returned by the Server.
+type LinksPage struct {
+	Total  int    `json:"total"`
+	Offset int    `json:"offset"`
+	Links  []Link `json:"links"`
+}
+
+// Server serves the graph page, which loads the nodes and links progressively from a JSON API:
+//
+//	GET /                                   the graph page (also /?host=... and /?url=...&hops=... for a subgraph)
+//	GET /api/nodes?offset=0&limit=1000      a page of nodes, sorted by index
+//	GET /api/links?offset=0&limit=1000      a page of links
+//	GET /api/subgraph?host=example.com      the nodes of a host and the links between them
+//	GET /api/subgraph?url=...&hops=1        the nodes up to a number of hops from a URL and the links between them
+type Server struct {
+	elements Elements
+	opts     ViewerOptions
+	mux      *http.ServeMux
+}
+
+// NewServer returns a new Server for the records passing the filter in the options, aggregated as set in them.
+// It returns ErrLibraryMissing if the library isn't embedded and the options don't set UseCDN.
+func NewServer(rm *wcrawler.RecordManager, opts ViewerOptions) (*Server, error) {
+	if err := checkLibrary(opts.UseCDN); err != nil {
+		return nil, err
+	}
+
+	elements, err := opts.elements(rm)
+	if err != nil {
+		return nil, err
+	}
+
+	s := Server{elements: Layout(elements, opts.Layout), opts: opts, mux: http.NewServeMux()}
+
+	s.mux.HandleFunc("/", s.handlePage)
+	s.mux.HandleFunc(libraryPath, handleLibrary)
+	s.mux.HandleFunc("/api/nodes", s.handleNodes)
+	s.mux.HandleFunc("/api/links", s.handleLinks)
+	s.mux.HandleFunc("/api/subgraph", s.handleSubgraph)
+
+	return &s, nil
+}
+
+// ServeHTTP dispatches the request to the endpoint for its path.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		httpjson.WriteError(w, http.StatusMethodNotAllowed, fmt.Errorf("method %s not allowed", r.Method))
+		return
+	}
+
+	s.mux.ServeHTTP(w, r)
+}
+
+func (s *Server) handlePage(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/" {
+		http.NotFound(w, r)
+		return
+	}
+
+	vars := servedPageVars(s.opts)
+	vars.Serve = true
+	vars.PageSize = defaultPageSize
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	executeTemplate(w, vars)
+}
+
+// servedPageVars returns the variables of the graph page loading the library from libraryPath, unless opts.UseCDN is set.
+// The servers check the library is embedded when they're created.
+func servedPageVars(opts ViewerOptions) templateVars {
+	vars := templateVars{
+		ColorBy:    colorMode(opts.ColorBy),
+		Layout:     layoutMode(opts.Layout),
+		LibraryURL: LibraryCDN,
+	}
+	if !opts.UseCDN {
+		vars.LibraryURL = libraryPath
+	}
+
+	return vars
+}
+
+// handleLibrary serves the embedded 3d-force-graph library.
+func handleLibrary(w http.ResponseWriter, r *http.Request) {
+	library, err := assets.ReadFile(libraryFile)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/javascript; charset=utf-8")
+	w.Write(library)
+}
+
+func (s *Server) handleNodes(w http.ResponseWriter, r *http.Request) {
+	offset, limit, err := pageParams(r)
+	if err != nil {
+		httpjson.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	start, end := pageBounds(len(s.elements.Nodes), offset, limit)
+	httpjson.Write(w, http.StatusOK, NodesPage{Total: len(s.elements.Nodes), Offset: offset, Nodes: s.elements.Nodes[start:end]})
+}
+
+func (s *Server) handleLinks(w http.ResponseWriter, r *http.Request) {
+	offset, limit, err := pageParams(r)
+	if err != nil {
+		httpjson.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	start, end := pageBounds(len(s.elements.Links), offset, limit)
+	httpjson.Write(w, http.StatusOK, LinksPage{Total: len(s.elements.Links), Offset: offset, Links: s.elements.Links[start:end]})
+}
+
+func (s *Server) handleSubgraph(w http.ResponseWriter, r *http.Request) {
+	host := r.FormValue("host")
+	url := r.FormValue("url")
+
+	switch {
+	case host != "" && url != "":
+		httpjson.WriteError(w, http.StatusBadRequest, fmt.Errorf("use either 'host' or 'url'"))
+	case host != "":
+		httpjson.Write(w, http.StatusOK, s.elements.Subgraph(func(node Node) bool { return node.Domain == host }))
+	case url != "":
+		hops, err := httpjson.OptionalIntParam(r, "hops", defaultHops)
+		if err != nil {
+			httpjson.WriteError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		sub, err := s.elements.Neighbourhood(url, hops)
+		if err != nil {
+			httpjson.WriteError(w, http.StatusNotFound, err)
+			return
+		}
+		httpjson.Write(w, http.StatusOK, sub)
+	default:
+		httpjson.WriteError(w, http.StatusBadRequest, fmt.Errorf("missing 'host' or 'url' parameter"))
+	}
+}
+
+// pageParams returns the offset and limit of the page requested.
+func pageParams(r *http.Request) (offset int, limit int, err error) {
+	offset, err = httpjson.OptionalIntParam(r, "offset", 0)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	limit, err = httpjson.OptionalIntParam(r, "limit", defaultPageSize)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	if limit > maxPageSize {
+		limit = maxPageSize
+	}
+
+	return offset, limit, nil
+}
+
+// pageBounds returns the start and end (exclusive) of a page within total items.
+func pageBounds(total int, offset int, limit int) (start int, end int) {
+	start = offset
+	if start > total {
+		start = total
+	}
+
+	end = start + limit
+	if end > total {
+		end = total
+	}
+
+	return start, end
+}
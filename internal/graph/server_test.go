@@ -0,0 +1,145 @@
+package graph_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gustavooferreira/wcrawler"
+	"github.com/gustavooferreira/wcrawler/internal/graph"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newRecordManager returns records of a small site:
+// home (0) -> about (1), contact (2); contact -> blog (3) -> post (4)
+func newRecordManager() *wcrawler.RecordManager {
+	rm := wcrawler.NewRecordManager()
+
+	entries := []wcrawler.RMEntry{
+		{ParentURL: "", URL: wcrawler.URLEntity{NetLoc: "example.com", Raw: "http://example.com/"}, Depth: 0, StatusCode: 200},
+		{ParentURL: "http://example.com/", URL: wcrawler.URLEntity{NetLoc: "example.com", Raw: "http://example.com/about"}, Depth: 1, StatusCode: 200},
+		{ParentURL: "http://example.com/", URL: wcrawler.URLEntity{NetLoc: "example.com", Raw: "http://example.com/contact"}, Depth: 1, StatusCode: 404},
+		{ParentURL: "http://example.com/contact", URL: wcrawler.URLEntity{NetLoc: "blog.example.com", Raw: "http://blog.example.com/"}, Depth: 2, StatusCode: 200},
+		{ParentURL: "http://blog.example.com/", URL: wcrawler.URLEntity{NetLoc: "blog.example.com", Raw: "http://blog.example.com/post"}, Depth: 3, StatusCode: 500},
+	}
+
+	for _, e := range entries {
+		rm.AddRecord(e)
+	}
+
+	rm.AddEdge("http://example.com/about", "http://example.com/")
+
+	return rm
+}
+
+func get(t *testing.T, handler http.Handler, target string, expectedStatusCode int, value interface{}) {
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, target, nil))
+
+	require.Equal(t, expectedStatusCode, w.Code, w.Body.String())
+	if value != nil {
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), value))
+	}
+}
+
+func TestServerPagination(t *testing.T) {
+	s, err := graph.NewServer(newRecordManager(), graph.ViewerOptions{UseCDN: true})
+	require.NoError(t, err)
+
+	var nodes graph.NodesPage
+	get(t, s, "/api/nodes?offset=1&limit=3", http.StatusOK, &nodes)
+	assert.Equal(t, 5, nodes.Total)
+	assert.Equal(t, 1, nodes.Offset)
+	require.Len(t, nodes.Nodes, 3)
+	assert.Equal(t, []string{"1", "2", "3"}, []string{nodes.Nodes[0].ID, nodes.Nodes[1].ID, nodes.Nodes[2].ID})
+
+	get(t, s, "/api/nodes?offset=4", http.StatusOK, &nodes)
+	assert.Len(t, nodes.Nodes, 1)
+
+	get(t, s, "/api/nodes?offset=10", http.StatusOK, &nodes)
+	assert.Empty(t, nodes.Nodes)
+
+	var links graph.LinksPage
+	get(t, s, "/api/links?limit=2", http.StatusOK, &links)
+	assert.Equal(t, 5, links.Total)
+	assert.Equal(t, []graph.Link{{Source: "0", Target: "1"}, {Source: "0", Target: "2"}}, links.Links)
+
+	get(t, s, "/api/nodes?limit=-1", http.StatusBadRequest, nil)
+	get(t, s, "/api/links?offset=a", http.StatusBadRequest, nil)
+}
+
+func TestServerSubgraph(t *testing.T) {
+	s, err := graph.NewServer(newRecordManager(), graph.ViewerOptions{UseCDN: true})
+	require.NoError(t, err)
+
+	tests := map[string]struct {
+		target             string
+		expectedStatusCode int
+		expectedNodes      []string
+		expectedLinks      int
+	}{
+		"host":             {target: "/api/subgraph?host=blog.example.com", expectedStatusCode: http.StatusOK, expectedNodes: []string{"3", "4"}, expectedLinks: 1},
+		"url default hops": {target: "/api/subgraph?url=http://example.com/contact", expectedStatusCode: http.StatusOK, expectedNodes: []string{"0", "2", "3"}, expectedLinks: 2},
+		"url 2 hops":       {target: "/api/subgraph?url=http://example.com/contact&hops=2", expectedStatusCode: http.StatusOK, expectedNodes: []string{"0", "1", "2", "3", "4"}, expectedLinks: 5},
+		"url 0 hops":       {target: "/api/subgraph?url=http://example.com/contact&hops=0", expectedStatusCode: http.StatusOK, expectedNodes: []string{"2"}, expectedLinks: 0},
+		"unknown url":      {target: "/api/subgraph?url=http://example.com/unknown", expectedStatusCode: http.StatusNotFound},
+		"missing params":   {target: "/api/subgraph", expectedStatusCode: http.StatusBadRequest},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var elements graph.Elements
+			if test.expectedStatusCode != http.StatusOK {
+				get(t, s, test.target, test.expectedStatusCode, nil)
+				return
+			}
+
+			get(t, s, test.target, test.expectedStatusCode, &elements)
+
+			ids := []string{}
+			for _, node := range elements.Nodes {
+				ids = append(ids, node.ID)
+			}
+			assert.Equal(t, test.expectedNodes, ids)
+			assert.Len(t, elements.Links, test.expectedLinks)
+		})
+	}
+}
+
+func TestServerPage(t *testing.T) {
+	s, err := graph.NewServer(newRecordManager(), graph.ViewerOptions{UseCDN: true})
+	require.NoError(t, err)
+
+	w := httptest.NewRecorder()
+	s.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), graph.LibraryCDN)
+	assert.Contains(t, w.Body.String(), "/api/nodes")
+	// Data isn't inlined
+	assert.NotContains(t, w.Body.String(), "http://example.com/about")
+
+	get(t, s, "/unknown", http.StatusNotFound, nil)
+	w = httptest.NewRecorder()
+	s.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/", nil))
+	assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+}
+
+func TestServerLibrary(t *testing.T) {
+	s, err := graph.NewServer(newRecordManager(), graph.ViewerOptions{})
+	if !graph.LibraryEmbedded() {
+		assert.ErrorIs(t, err, graph.ErrLibraryMissing)
+		return
+	}
+	require.NoError(t, err)
+
+	w := httptest.NewRecorder()
+	s.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.NotContains(t, w.Body.String(), graph.LibraryCDN)
+
+	w = httptest.NewRecorder()
+	s.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/assets/3d-force-graph.min.js", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+}
@@ -0,0 +1,198 @@
+package graph
+
+import (
+	_ "embed"
+	"fmt"
+	"html/template"
+	"io"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+//go:embed "site_tree.tmpl"
+var siteTreeTemplate string
+
+// statusClasses lists the status classes in the order they're summarised.
+var statusClasses = []string{"2xx", "3xx", "4xx", "5xx", StatusClassError, StatusClassUnvisited}
+
+// SiteTree represents the crawled pages organised by URL path, with a tree per host.
+type SiteTree struct {
+	// Hosts are the roots of the trees, sorted by host
+	Hosts []*TreeNode
+}
+
+// TreeNode represents a URL path, with a summary of the pages at or under it.
+type TreeNode struct {
+	// Name is the host for the root of a host, the last segment of the path otherwise
+	Name string
+	// Path is the URL path of the node (e.g. "/blog/2021")
+	Path string
+	// Pages is the number of pages at or under the path
+	Pages int
+	// Statuses counts the pages at or under the path by status class
+	Statuses map[string]int
+	// Broken is the number of pages at or under the path that couldn't be fetched (4xx, 5xx or error)
+	Broken int
+	// URLs lists the pages at exactly this path (more than one if they only differ in the query string)
+	URLs []string
+	// Children are sorted by name
+	Children []*TreeNode
+
+	children map[string]*TreeNode
+}
+
+// NewSiteTree builds the tree of the URL paths of the nodes. Nodes with invalid URLs are left out.
+func NewSiteTree(elements Elements) *SiteTree {
+	hosts := map[string]*TreeNode{}
+
+	for _, n := range elements.Nodes {
+		u, err := url.Parse(n.URL)
+		if err != nil || u.Host == "" {
+			continue
+		}
+
+		node, ok := hosts[u.Host]
+		if !ok {
+			node = newTreeNode(u.Host, "/")
+			hosts[u.Host] = node
+		}
+		broken := contains(ErrorStatusClasses, n.StatusClass)
+		node.count(n.StatusClass, broken)
+
+		path := ""
+		for _, segment := range strings.Split(u.Path, "/") {
+			if segment == "" {
+				continue
+			}
+			path += "/" + segment
+
+			child, ok := node.children[segment]
+			if !ok {
+				child = newTreeNode(segment, path)
+				node.children[segment] = child
+			}
+			node = child
+			node.count(n.StatusClass, broken)
+		}
+
+		node.URLs = append(node.URLs, n.URL)
+	}
+
+	tree := &SiteTree{}
+	for _, node := range hosts {
+		node.sort()
+		tree.Hosts = append(tree.Hosts, node)
+	}
+	sort.Slice(tree.Hosts, func(i, j int) bool { return tree.Hosts[i].Name < tree.Hosts[j].Name })
+
+	return tree
+}
+
+func newTreeNode(name string, path string) *TreeNode {
+	return &TreeNode{Name: name, Path: path, Statuses: map[string]int{}, children: map[string]*TreeNode{}}
+}
+
+// count adds a page at or under the path.
+func (n *TreeNode) count(statusClass string, broken bool) {
+	n.Pages++
+	n.Statuses[statusClass]++
+	if broken {
+		n.Broken++
+	}
+}
+
+// sort sets the children, sorted by name, of the node and its descendants.
+func (n *TreeNode) sort() {
+	n.Children = make([]*TreeNode, 0, len(n.children))
+	for _, child := range n.children {
+		child.sort()
+		n.Children = append(n.Children, child)
+	}
+	sort.Slice(n.Children, func(i, j int) bool { return n.Children[i].Name < n.Children[j].Name })
+}
+
+// Label returns the name of the node followed by a summary of the pages at or under it,
+// e.g. "blog/  12 pages [2xx: 10, 4xx: 2]  ✗ 2 broken", or "about  [2xx]" for a single page without children.
+func (n *TreeNode) Label() string {
+	var sb strings.Builder
+
+	if len(n.Children) == 0 && n.Pages == 1 {
+		for class := range n.Statuses {
+			fmt.Fprintf(&sb, "%s  [%s]", n.Name, class)
+		}
+		if n.Broken != 0 {
+			sb.WriteString("  ✗")
+		}
+		return sb.String()
+	}
+
+	sb.WriteString(n.Name)
+	if len(n.Children) != 0 && n.Path != "/" {
+		sb.WriteString("/")
+	}
+	pages := "pages"
+	if n.Pages == 1 {
+		pages = "page"
+	}
+	fmt.Fprintf(&sb, "  %d %s [%s]", n.Pages, pages, n.StatusSummary())
+	if n.Broken != 0 {
+		fmt.Fprintf(&sb, "  ✗ %d broken", n.Broken)
+	}
+
+	return sb.String()
+}
+
+// StatusSummary returns the number of pages by status class, e.g. "2xx: 10, 4xx: 2".
+func (n *TreeNode) StatusSummary() string {
+	var parts []string
+	for _, class := range statusClasses {
+		if count := n.Statuses[class]; count != 0 {
+			parts = append(parts, fmt.Sprintf("%s: %d", class, count))
+		}
+	}
+
+	return strings.Join(parts, ", ")
+}
+
+// WriteText writes the tree, expanded up to the given number of levels under the hosts (0 means all of them).
+// Collapsed nodes say how many entries are hidden under them.
+func (t *SiteTree) WriteText(w io.Writer, levels int) error {
+	var sb strings.Builder
+
+	for _, host := range t.Hosts {
+		sb.WriteString(host.Label() + "\n")
+		writeTextChildren(&sb, host, "", 1, levels)
+	}
+
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+func writeTextChildren(sb *strings.Builder, n *TreeNode, prefix string, level int, levels int) {
+	for i, child := range n.Children {
+		connector, indent := "├── ", "│   "
+		if i == len(n.Children)-1 {
+			connector, indent = "└── ", "    "
+		}
+
+		sb.WriteString(prefix + connector + child.Label())
+		if len(child.Children) != 0 && levels != 0 && level >= levels {
+			fmt.Fprintf(sb, "  (%d entries hidden)\n", len(child.Children))
+			continue
+		}
+		sb.WriteString("\n")
+
+		writeTextChildren(sb, child, prefix+indent, level+1, levels)
+	}
+}
+
+// WriteHTML writes the tree as an HTML page, with collapsible paths. Hosts are expanded.
+func (t *SiteTree) WriteHTML(w io.Writer) error {
+	tmpl, err := template.New("tree").Parse(siteTreeTemplate)
+	if err != nil {
+		return err
+	}
+
+	return tmpl.Execute(w, t)
+}
@@ -0,0 +1,146 @@
+package graph_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/gustavooferreira/wcrawler/internal/graph"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var siteTreeElements = graph.Elements{
+	Nodes: []graph.Node{
+		{URL: "http://example.com/", StatusClass: "2xx"},
+		{URL: "http://example.com/blog/", StatusClass: "2xx"},
+		{URL: "http://example.com/blog/first-post", StatusClass: "2xx"},
+		{URL: "http://example.com/blog/old-post", StatusClass: "4xx"},
+		{URL: "http://example.com/search?q=go", StatusClass: "2xx"},
+		{URL: "http://example.com/search?q=rust", StatusClass: "5xx"},
+		{URL: "http://a.example.com/about", StatusClass: "error"},
+		{URL: "not a URL", StatusClass: "unvisited"},
+	},
+}
+
+func TestNewSiteTree(t *testing.T) {
+	tree := graph.NewSiteTree(siteTreeElements)
+
+	require.Len(t, tree.Hosts, 2)
+	assert.Equal(t, "a.example.com", tree.Hosts[0].Name)
+
+	root := tree.Hosts[1]
+	assert.Equal(t, "example.com", root.Name)
+	assert.Equal(t, "/", root.Path)
+	assert.Equal(t, 6, root.Pages)
+	assert.Equal(t, 2, root.Broken)
+	assert.Equal(t, map[string]int{"2xx": 4, "4xx": 1, "5xx": 1}, root.Statuses)
+	assert.Equal(t, []string{"http://example.com/"}, root.URLs)
+
+	require.Len(t, root.Children, 2)
+	blog := root.Children[0]
+	assert.Equal(t, "blog", blog.Name)
+	assert.Equal(t, "/blog", blog.Path)
+	assert.Equal(t, 3, blog.Pages)
+	assert.Equal(t, 1, blog.Broken)
+	assert.Equal(t, "2xx: 2, 4xx: 1", blog.StatusSummary())
+	require.Len(t, blog.Children, 2)
+	assert.Equal(t, "/blog/old-post", blog.Children[1].Path)
+
+	// Pages only differing in the query string share the path
+	search := root.Children[1]
+	assert.Equal(t, []string{"http://example.com/search?q=go", "http://example.com/search?q=rust"}, search.URLs)
+	assert.Empty(t, search.Children)
+}
+
+func TestSiteTreeWriteText(t *testing.T) {
+	tree := graph.NewSiteTree(siteTreeElements)
+
+	tests := map[string]struct {
+		levels   int
+		expected string
+	}{
+		"all levels": {
+			levels: 0,
+			expected: "a.example.com  1 page [error: 1]  ✗ 1 broken\n" +
+				"└── about  [error]  ✗\n" +
+				"example.com  6 pages [2xx: 4, 4xx: 1, 5xx: 1]  ✗ 2 broken\n" +
+				"├── blog/  3 pages [2xx: 2, 4xx: 1]  ✗ 1 broken\n" +
+				"│   ├── first-post  [2xx]\n" +
+				"│   └── old-post  [4xx]  ✗\n" +
+				"└── search  2 pages [2xx: 1, 5xx: 1]  ✗ 1 broken\n",
+		},
+		"one level": {
+			levels: 1,
+			expected: "a.example.com  1 page [error: 1]  ✗ 1 broken\n" +
+				"└── about  [error]  ✗\n" +
+				"example.com  6 pages [2xx: 4, 4xx: 1, 5xx: 1]  ✗ 2 broken\n" +
+				"├── blog/  3 pages [2xx: 2, 4xx: 1]  ✗ 1 broken  (2 entries hidden)\n" +
+				"└── search  2 pages [2xx: 1, 5xx: 1]  ✗ 1 broken\n",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var buf bytes.Buffer
+			require.NoError(t, tree.WriteText(&buf, test.levels))
+			assert.Equal(t, test.expected, buf.String())
+		})
+	}
+}
+
+func TestSiteTreeWriteHTML(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, graph.NewSiteTree(siteTreeElements).WriteHTML(&buf))
+
+	html := buf.String()
+	assert.Equal(t, 3, strings.Count(html, "<details"))
+	assert.Contains(t, html, `<a href="http://example.com/blog/old-post">old-post</a>`)
+	assert.Contains(t, html, `<span class="broken">✗ 2 broken</span>`)
+}
+
+func TestTreeBrowser(t *testing.T) {
+	tree := graph.NewSiteTree(siteTreeElements)
+
+	tests := map[string]struct {
+		input       string
+		contains    []string
+		notContains []string
+	}{
+		"hosts expanded": {
+			input:       "q",
+			contains:    []string{"▾ example.com", "▸ blog/"},
+			notContains: []string{"first-post"},
+		},
+		"expand with the arrow keys": {
+			// a.example.com, about, example.com, blog
+			input:    "\x1b[B\x1b[B\x1b[B\x1b[C",
+			contains: []string{"▾ blog/", "first-post"},
+		},
+		"collapse a host": {
+			input:       "\x1b[D",
+			contains:    []string{"▸ a.example.com"},
+			notContains: []string{"about"},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var buf bytes.Buffer
+			graph.NewTreeBrowser(tree, &buf, func() (int, int) { return 100, 20 }).Run(strings.NewReader(test.input))
+
+			// The last frame drawn
+			output := buf.String()
+			frame := output[strings.LastIndex(output, "\x1b[2J"):]
+			for _, s := range test.contains {
+				assert.Contains(t, frame, s)
+			}
+			for _, s := range test.notContains {
+				assert.NotContains(t, frame, s)
+			}
+
+			// The terminal is left as it was found
+			assert.True(t, strings.HasSuffix(output, "\x1b[?25h\x1b[?1049l"))
+		})
+	}
+}
@@ -0,0 +1,64 @@
+package graph
+
+import "fmt"
+
+// Subgraph returns the nodes for which keep returns true and the links between them.
+func (e Elements) Subgraph(keep func(node Node) bool) Elements {
+	kept := map[string]bool{}
+	sub := Elements{Nodes: []Node{}, Links: []Link{}}
+
+	for _, node := range e.Nodes {
+		if keep(node) {
+			kept[node.ID] = true
+			sub.Nodes = append(sub.Nodes, node)
+		}
+	}
+
+	for _, link := range e.Links {
+		if kept[link.Source] && kept[link.Target] {
+			sub.Links = append(sub.Links, link)
+		}
+	}
+
+	return sub
+}
+
+// Neighbourhood returns the subgraph of the nodes up to a number of hops away from the node with the given URL,
+// following links in both directions.
+func (e Elements) Neighbourhood(url string, hops int) (Elements, error) {
+	start := ""
+	for _, node := range e.Nodes {
+		if node.URL == url {
+			start = node.ID
+			break
+		}
+	}
+
+	if start == "" {
+		return Elements{}, fmt.Errorf("URL %q not found", url)
+	}
+
+	adjacency := map[string][]string{}
+	for _, link := range e.Links {
+		adjacency[link.Source] = append(adjacency[link.Source], link.Target)
+		adjacency[link.Target] = append(adjacency[link.Target], link.Source)
+	}
+
+	// Breadth-first search, up to the number of hops
+	visited := map[string]bool{start: true}
+	frontier := []string{start}
+	for i := 0; i < hops && len(frontier) != 0; i++ {
+		var next []string
+		for _, id := range frontier {
+			for _, neighbour := range adjacency[id] {
+				if !visited[neighbour] {
+					visited[neighbour] = true
+					next = append(next, neighbour)
+				}
+			}
+		}
+		frontier = next
+	}
+
+	return e.Subgraph(func(node Node) bool { return visited[node.ID] }), nil
+}
@@ -0,0 +1,188 @@
+package graph
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/gustavooferreira/wcrawler/internal/term"
+)
+
+// treeRow represents a node shown by the TreeBrowser.
+type treeRow struct {
+	node  *TreeNode
+	level int
+	// parent is the index of the row of the parent node (-1 for hosts)
+	parent int
+}
+
+// TreeBrowser draws a SiteTree on a terminal, full-screen, with paths expanded and collapsed with the keys:
+//
+//	↑ / ↓ (or k / j)            select the previous or next path
+//	→ / enter / space (or l)    expand the selected path (enter and space toggle it)
+//	← (or h)                    collapse the selected path, or select its parent
+//	q / Ctrl-C                  quit
+type TreeBrowser struct {
+	tree   *SiteTree
+	writer io.Writer
+	// size returns the width and height of the terminal
+	size func() (width int, height int)
+
+	expanded map[*TreeNode]bool
+	// selected is the index of the selected row, offset the index of the first row drawn
+	selected int
+	offset   int
+}
+
+// NewTreeBrowser returns a new TreeBrowser, with the hosts expanded.
+// size returns the size of the terminal, if nil the tree is drawn for a 80x24 terminal.
+func NewTreeBrowser(tree *SiteTree, writer io.Writer, size func() (width int, height int)) *TreeBrowser {
+	if size == nil {
+		size = func() (int, int) { return 80, 24 }
+	}
+
+	tb := TreeBrowser{tree: tree, writer: writer, size: size, expanded: map[*TreeNode]bool{}}
+	for _, host := range tree.Hosts {
+		tb.expanded[host] = true
+	}
+
+	return &tb
+}
+
+// Run draws the tree on the terminal's alternate screen and reads keys from the reader (a terminal in raw mode),
+// until 'q' or Ctrl-C is pressed or the reader returns an error. The terminal is left on the main screen.
+func (tb *TreeBrowser) Run(reader io.Reader) {
+	// Switch to the alternate screen and hide the cursor
+	fmt.Fprint(tb.writer, "\x1b[?1049h\x1b[?25l")
+	defer fmt.Fprint(tb.writer, "\x1b[?25h\x1b[?1049l")
+
+	fmt.Fprint(tb.writer, tb.frame())
+
+	term.ReadKeys(reader, func(key byte) bool {
+		if key == 'q' || key == term.KeyCtrlC {
+			return false
+		}
+
+		tb.handleKey(key)
+		fmt.Fprint(tb.writer, tb.frame())
+		return true
+	})
+}
+
+// handleKey applies the action bound to a key.
+func (tb *TreeBrowser) handleKey(key byte) {
+	rows := tb.rows()
+	if len(rows) == 0 {
+		return
+	}
+	row := rows[tb.selected]
+
+	switch key {
+	case 'k':
+		if tb.selected > 0 {
+			tb.selected--
+		}
+	case 'j':
+		if tb.selected < len(rows)-1 {
+			tb.selected++
+		}
+	case 'l':
+		if len(row.node.Children) != 0 {
+			tb.expanded[row.node] = true
+		}
+	case '\r', '\n', ' ':
+		if len(row.node.Children) != 0 {
+			tb.expanded[row.node] = !tb.expanded[row.node]
+		}
+	case 'h':
+		if tb.expanded[row.node] {
+			tb.expanded[row.node] = false
+		} else if row.parent != -1 {
+			tb.selected = row.parent
+		}
+	}
+}
+
+// rows returns the nodes shown, those whose ancestors are all expanded.
+func (tb *TreeBrowser) rows() []treeRow {
+	var rows []treeRow
+
+	var add func(n *TreeNode, level int, parent int)
+	add = func(n *TreeNode, level int, parent int) {
+		rows = append(rows, treeRow{node: n, level: level, parent: parent})
+		if !tb.expanded[n] {
+			return
+		}
+
+		index := len(rows) - 1
+		for _, child := range n.Children {
+			add(child, level+1, index)
+		}
+	}
+
+	for _, host := range tb.tree.Hosts {
+		add(host, 0, -1)
+	}
+
+	return rows
+}
+
+// frame returns the escape sequences and text drawing the tree, scrolled to show the selected row.
+func (tb *TreeBrowser) frame() string {
+	width, height := tb.size()
+	rows := tb.rows()
+
+	// Keep the selected row in view, below the header
+	visible := height - 2
+	if visible < 1 {
+		visible = 1
+	}
+	if tb.selected < tb.offset {
+		tb.offset = tb.selected
+	}
+	if tb.selected >= tb.offset+visible {
+		tb.offset = tb.selected - visible + 1
+	}
+
+	var sb strings.Builder
+	// Move to the top left corner and clear the screen
+	sb.WriteString("\x1b[H\x1b[2J")
+	sb.WriteString(truncate("Site tree  ↑/↓ move, →/← expand/collapse, enter toggle, q quit", width) + "\r\n\r\n")
+
+	for i := tb.offset; i < len(rows) && i < tb.offset+visible; i++ {
+		row := rows[i]
+
+		marker := "  "
+		if len(row.node.Children) != 0 {
+			marker = "▸ "
+			if tb.expanded[row.node] {
+				marker = "▾ "
+			}
+		}
+
+		line := truncate(strings.Repeat("  ", row.level)+marker+row.node.Label(), width)
+		if i == tb.selected {
+			// Reverse video
+			line = "\x1b[7m" + line + "\x1b[0m"
+		}
+		sb.WriteString(line)
+		if i < tb.offset+visible-1 {
+			sb.WriteString("\r\n")
+		}
+	}
+
+	return sb.String()
+}
+
+// truncate cuts the text to the width, in runes.
+func truncate(s string, width int) string {
+	runes := []rune(s)
+	if len(runes) <= width {
+		return s
+	}
+	if width < 1 {
+		return ""
+	}
+
+	return string(runes[:width-1]) + "…"
+}
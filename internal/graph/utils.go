@@ -15,7 +15,9 @@ import (
 var htmlTemplate string
 
 // GenerateHTML generates a new HTML file with the loaded data.
-func GenerateHTML(elements Elements, w io.Writer) error {
+// The 3d-force-graph library is inlined, so the file is self-contained, unless opts.UseCDN is set
+// (then the library is loaded from LibraryCDN when the file is opened). The filter in the options isn't applied.
+func GenerateHTML(elements Elements, w io.Writer, opts ViewerOptions) error {
 	// Take the info from gg struct and json indent it to a string
 
 	jsonString, err := json.MarshalIndent(elements, "", "    ")
@@ -23,12 +25,67 @@ func GenerateHTML(elements Elements, w io.Writer) error {
 		return err
 	}
 
-	vars := struct {
-		Elements string
-	}{
-		Elements: string(jsonString),
+	vars := templateVars{
+		Elements:   string(jsonString),
+		ColorBy:    colorMode(opts.ColorBy),
+		Layout:     layoutMode(opts.Layout),
+		LibraryURL: LibraryCDN,
 	}
 
+	if !opts.UseCDN {
+		library, ok := embeddedLibrary()
+		if !ok {
+			return ErrLibraryMissing
+		}
+		vars.Library = library
+	}
+
+	return executeTemplate(w, vars)
+}
+
+// What nodes can be coloured by in the graph page.
+const (
+	ColorByDomain      = "domain"
+	ColorByStatus      = "status"
+	ColorByDepth       = "depth"
+	ColorByContentType = "contentType"
+	ColorByError       = "error"
+)
+
+// ColorModes lists what nodes can be coloured by.
+var ColorModes = []string{ColorByDomain, ColorByStatus, ColorByDepth, ColorByContentType, ColorByError}
+
+// colorMode returns the colour mode, or ColorByDomain if it's not one of ColorModes.
+func colorMode(colorBy string) string {
+	for _, mode := range ColorModes {
+		if mode == colorBy {
+			return mode
+		}
+	}
+
+	return ColorByDomain
+}
+
+// templateVars represents the variables of the HTML template.
+type templateVars struct {
+	// Elements are the nodes and links as JSON (unless served)
+	Elements string
+	// ColorBy is what nodes are coloured by when the page is opened (one of ColorModes)
+	ColorBy string
+	// Layout is how nodes are laid out (one of Layouts)
+	Layout string
+	// Library is the inlined 3d-force-graph library, loaded from LibraryURL if empty
+	Library    string
+	LibraryURL string
+
+	// Serve is set when the data is loaded from a Server, PageSize nodes or links at a time
+	Serve    bool
+	PageSize int
+	// Live is set when the data is streamed by a LiveServer as the crawl runs
+	Live bool
+}
+
+func executeTemplate(w io.Writer, vars templateVars) error {
 	t, err := template.New("graph").Parse(htmlTemplate)
 	if err != nil {
 		return err
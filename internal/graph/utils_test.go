@@ -1,9 +1,12 @@
 package graph_test
 
 import (
+	"bytes"
 	"testing"
 
+	"github.com/gustavooferreira/wcrawler/internal/graph"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestHTMLTemplateString(t *testing.T) {
@@ -19,3 +22,40 @@ func TestGeneration(t *testing.T) {
 
 	assert.Equal(t, "", "")
 }
+
+func TestGenerateHTML(t *testing.T) {
+	elements := graph.Elements{
+		Nodes: []graph.Node{{ID: "0", URL: "http://example.com/", Domain: "example.com"}},
+	}
+
+	t.Run("cdn", func(t *testing.T) {
+		var buf bytes.Buffer
+		err := graph.GenerateHTML(elements, &buf, graph.ViewerOptions{UseCDN: true, ColorBy: graph.ColorByStatus})
+		require.NoError(t, err)
+
+		assert.Contains(t, buf.String(), `<script src="`+graph.LibraryCDN+`"></script>`)
+		assert.Contains(t, buf.String(), `"url": "http://example.com/"`)
+	})
+
+	t.Run("inline", func(t *testing.T) {
+		if !graph.LibraryEmbedded() {
+			t.Skip("the library isn't vendored in internal/graph/assets (run 'make assets')")
+		}
+
+		var buf bytes.Buffer
+		err := graph.GenerateHTML(elements, &buf, graph.ViewerOptions{})
+		require.NoError(t, err)
+		assert.NotContains(t, buf.String(), graph.LibraryCDN)
+		assert.Contains(t, buf.String(), `"url": "http://example.com/"`)
+	})
+
+	t.Run("missing library", func(t *testing.T) {
+		if graph.LibraryEmbedded() {
+			t.Skip("the library is vendored in internal/graph/assets")
+		}
+
+		var buf bytes.Buffer
+		err := graph.GenerateHTML(elements, &buf, graph.ViewerOptions{})
+		assert.ErrorIs(t, err, graph.ErrLibraryMissing)
+	})
+}
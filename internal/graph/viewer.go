@@ -1,7 +1,9 @@
 package graph
 
 import (
+	"encoding/json"
 	"io"
+	"sort"
 	"strconv"
 
 	"github.com/gustavooferreira/wcrawler"
@@ -10,10 +12,30 @@ import (
 type Viewer struct {
 	reader io.Reader
 	writer io.Writer
+	opts   ViewerOptions
 }
 
-func NewViewer(r io.Reader, w io.Writer) *Viewer {
-	v := &Viewer{reader: r, writer: w}
+// ViewerOptions represents the options of the generated graph.
+type ViewerOptions struct {
+	// UseCDN loads the 3d-force-graph library from LibraryCDN instead of inlining it
+	UseCDN bool
+	// Filter restricts the nodes in the graph
+	Filter Filter
+	// ColorBy is what nodes are coloured by when the page is opened (one of ColorModes, domain by default)
+	ColorBy string
+	// Layout is how nodes are laid out (one of Layouts, 3d by default)
+	Layout string
+	// Format is what the graph is written as (one of Formats, HTML by default)
+	Format string
+	// Aggregate collapses the pages into one node per host or registered domain (one of Aggregations),
+	// after filtering them. Pages aren't aggregated if empty.
+	Aggregate string
+	// Labels is the number of nodes labelled with their URL in images, by highest in-degree
+	Labels int
+}
+
+func NewViewer(r io.Reader, w io.Writer, opts ViewerOptions) *Viewer {
+	v := &Viewer{reader: r, writer: w, opts: opts}
 	return v
 }
 
@@ -26,18 +48,67 @@ func (v *Viewer) Run() error {
 		return err
 	}
 
-	records := rm.Dump()
+	elements, err := v.opts.elements(rm)
+	if err != nil {
+		return err
+	}
+
+	switch v.opts.Format {
+	case FormatJSON:
+		return writeElements(elements, v.writer)
+	case FormatSVG:
+		return RenderSVG(elements, v.writer, v.opts)
+	case FormatPNG:
+		return RenderPNG(elements, v.writer, v.opts)
+	case FormatTree:
+		return NewSiteTree(elements).WriteHTML(v.writer)
+	}
+
+	return GenerateHTML(Layout(elements, v.opts.Layout), v.writer, v.opts)
+}
+
+// elements returns the nodes and links of the records passing the filter, aggregated as set in the options.
+func (opts ViewerOptions) elements(rm *wcrawler.RecordManager) (Elements, error) {
+	elements, err := opts.Filter.Apply(rm, NewElements(rm))
+	if err != nil {
+		return Elements{}, err
+	}
+
+	return Aggregate(elements, opts.Aggregate), nil
+}
+
+// writeElements writes the nodes and links as indented JSON.
+func writeElements(elements Elements, w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+
+	return enc.Encode(elements)
+}
+
+// NewElements creates the nodes and links, as expected by the 3d js library, from the records.
+// Nodes are sorted by index, so they can be paginated.
+func NewElements(rm *wcrawler.RecordManager) Elements {
+	records := make([]wcrawler.Record, 0, len(rm.Records))
+	for _, r := range rm.Dump() {
+		records = append(records, r)
+	}
+	sort.Slice(records, func(i, j int) bool { return records[i].Index < records[j].Index })
 
-	// Create struct as expected by 3d js library
 	nodes := []Node{}
 	links := []Link{}
 
 	idMapping := map[int]string{}
 
+	inDegree := map[int]int{}
+	for _, r := range records {
+		for _, edge := range r.Edges.Dump() {
+			inDegree[edge]++
+		}
+	}
+
 	// Nodes
 	for _, r := range records {
-		node := Node{ID: strconv.Itoa(int(r.Index)), URL: r.URL, Domain: r.Host, LinksCount: r.Edges.Count()}
-		nodes = append(nodes, node)
+		nodes = append(nodes, newNode(r, inDegree[r.Index]))
 
 		idMapping[r.Index] = strconv.Itoa(int(r.Index))
 	}
@@ -54,12 +125,29 @@ func (v *Viewer) Run() error {
 		}
 	}
 
-	elements := Elements{Nodes: nodes, Links: links}
+	return Elements{Nodes: nodes, Links: links}
+}
 
-	err = GenerateHTML(elements, v.writer)
-	if err != nil {
-		return err
+// newNode returns the node of the record.
+func newNode(r wcrawler.Record, inDegree int) Node {
+	node := Node{
+		ID:          strconv.Itoa(int(r.Index)),
+		URL:         r.URL,
+		Domain:      r.Host,
+		LinksCount:  r.Edges.Count(),
+		InDegree:    inDegree,
+		Depth:       r.Depth,
+		StatusCode:  r.StatusCode,
+		StatusClass: StatusClass(r),
+		Error:       r.ErrString,
+		ContentType: r.ContentType,
+		Size:        r.Size,
+		FetchedAt:   r.FetchedAt,
+		Scores:      r.Scores,
+	}
+	if r.Timings != nil {
+		node.TotalTime = r.Timings.Total.Seconds()
 	}
 
-	return nil
+	return node
 }
@@ -0,0 +1,39 @@
+package graph_test
+
+import (
+	"testing"
+
+	"github.com/gustavooferreira/wcrawler"
+	"github.com/gustavooferreira/wcrawler/internal/graph"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewElements(t *testing.T) {
+	rm := newRecordManager()
+	err := rm.SetResponseInfo("http://example.com/about", wcrawler.ResponseInfo{BytesRead: 512, ContentType: "text/html"})
+	require.NoError(t, err)
+
+	elements := graph.NewElements(rm)
+	require.Len(t, elements.Nodes, 5)
+
+	nodes := map[string]graph.Node{}
+	for _, n := range elements.Nodes {
+		nodes[n.URL] = n
+	}
+
+	root := nodes["http://example.com/"]
+	assert.Equal(t, 1, root.InDegree)
+	assert.Equal(t, 0, root.Depth)
+	assert.Equal(t, "2xx", root.StatusClass)
+
+	about := nodes["http://example.com/about"]
+	assert.Equal(t, 1, about.LinksCount)
+	assert.Equal(t, "text/html", about.ContentType)
+	assert.Equal(t, int64(512), about.Size)
+
+	post := nodes["http://blog.example.com/post"]
+	assert.Equal(t, 3, post.Depth)
+	assert.Equal(t, 500, post.StatusCode)
+	assert.Equal(t, "5xx", post.StatusClass)
+}
@@ -0,0 +1,191 @@
+// Package histogram provides a streaming histogram to compute quantiles of durations.
+package histogram
+
+import (
+	"math"
+	"time"
+)
+
+// precision represents the max relative error of the values returned by Quantile.
+const precision = 0.01
+
+// logBase is used to map values to buckets (each bucket is 'precision' wider than the previous one).
+var logBase = math.Log1p(precision)
+
+// Histogram records durations in logarithmic buckets (HDR-style), so quantiles can be
+// computed with a bounded relative error using a small, fixed amount of memory.
+// Histogram is not safe for concurrent use.
+type Histogram struct {
+	// counts holds the number of samples per bucket
+	counts []uint64
+	count  uint64
+	sum    time.Duration
+	min    time.Duration
+	max    time.Duration
+}
+
+// New returns a new Histogram.
+func New() *Histogram {
+	return &Histogram{}
+}
+
+// Add records a new sample. Negative durations are recorded as zero.
+func (h *Histogram) Add(value time.Duration) {
+	if value < 0 {
+		value = 0
+	}
+
+	index := bucketIndex(value)
+	if index >= len(h.counts) {
+		counts := make([]uint64, index+1)
+		copy(counts, h.counts)
+		h.counts = counts
+	}
+	h.counts[index]++
+
+	if h.count == 0 || value < h.min {
+		h.min = value
+	}
+
+	if value > h.max {
+		h.max = value
+	}
+
+	h.count++
+	h.sum += value
+}
+
+// Count returns the number of samples recorded.
+func (h *Histogram) Count() uint64 {
+	return h.count
+}
+
+// Sum returns the sum of the samples recorded.
+func (h *Histogram) Sum() time.Duration {
+	return h.sum
+}
+
+// CountAtMost returns the number of samples less than or equal to value.
+// Samples in the same bucket as value are all counted, so the bound has the relative error of Quantile.
+func (h *Histogram) CountAtMost(value time.Duration) uint64 {
+	if value < 0 {
+		return 0
+	}
+
+	last := bucketIndex(value)
+	var count uint64
+	for index := 0; index <= last && index < len(h.counts); index++ {
+		count += h.counts[index]
+	}
+
+	return count
+}
+
+// Min returns the smallest sample recorded.
+func (h *Histogram) Min() time.Duration {
+	return h.min
+}
+
+// Max returns the largest sample recorded.
+func (h *Histogram) Max() time.Duration {
+	return h.max
+}
+
+// Mean returns the average of the samples recorded (zero if there are no samples).
+func (h *Histogram) Mean() time.Duration {
+	if h.count == 0 {
+		return 0
+	}
+
+	return h.sum / time.Duration(h.count)
+}
+
+// Quantile returns the value below which the fraction q (between 0 and 1) of the samples fall.
+// Returns zero if there are no samples.
+func (h *Histogram) Quantile(q float64) time.Duration {
+	if h.count == 0 {
+		return 0
+	}
+
+	if q <= 0 {
+		return h.min
+	}
+
+	if q >= 1 {
+		return h.max
+	}
+
+	rank := uint64(math.Ceil(q * float64(h.count)))
+	var cumulative uint64
+
+	for index, c := range h.counts {
+		cumulative += c
+		if cumulative >= rank {
+			value := bucketValue(index)
+
+			// Values can't be outside the range recorded
+			if value < h.min {
+				return h.min
+			}
+
+			if value > h.max {
+				return h.max
+			}
+
+			return value
+		}
+	}
+
+	return h.max
+}
+
+// Merge adds all samples recorded in another histogram to this one.
+func (h *Histogram) Merge(other *Histogram) {
+	if other.count == 0 {
+		return
+	}
+
+	if len(other.counts) > len(h.counts) {
+		counts := make([]uint64, len(other.counts))
+		copy(counts, h.counts)
+		h.counts = counts
+	}
+
+	for index, c := range other.counts {
+		h.counts[index] += c
+	}
+
+	if h.count == 0 || other.min < h.min {
+		h.min = other.min
+	}
+
+	if other.max > h.max {
+		h.max = other.max
+	}
+
+	h.count += other.count
+	h.sum += other.sum
+}
+
+// bucketIndex returns the bucket a value (in microseconds) belongs to.
+// Values below one microsecond all go to the first bucket.
+func bucketIndex(value time.Duration) int {
+	us := float64(value) / float64(time.Microsecond)
+	if us < 1 {
+		return 0
+	}
+
+	return int(math.Log(us)/logBase) + 1
+}
+
+// bucketValue returns the value representing a bucket (its midpoint).
+func bucketValue(index int) time.Duration {
+	if index == 0 {
+		return 0
+	}
+
+	lower := math.Exp(float64(index-1) * logBase)
+	upper := math.Exp(float64(index) * logBase)
+
+	return time.Duration((lower + upper) / 2 * float64(time.Microsecond))
+}
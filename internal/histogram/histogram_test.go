@@ -0,0 +1,89 @@
+package histogram_test
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/gustavooferreira/wcrawler/internal/histogram"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHistogramQuantiles(t *testing.T) {
+	h := histogram.New()
+
+	// 1ms, 2ms, ..., 1000ms
+	for i := 1; i <= 1000; i++ {
+		h.Add(time.Duration(i) * time.Millisecond)
+	}
+
+	tests := map[string]struct {
+		q        float64
+		expected time.Duration
+	}{
+		"p0":   {q: 0, expected: time.Millisecond},
+		"p50":  {q: 0.5, expected: 500 * time.Millisecond},
+		"p90":  {q: 0.9, expected: 900 * time.Millisecond},
+		"p99":  {q: 0.99, expected: 990 * time.Millisecond},
+		"p999": {q: 0.999, expected: 999 * time.Millisecond},
+		"p100": {q: 1, expected: 1000 * time.Millisecond},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			value := h.Quantile(test.q)
+			assert.InEpsilon(t, float64(test.expected), float64(value), 0.01)
+		})
+	}
+
+	assert.Equal(t, uint64(1000), h.Count())
+	assert.Equal(t, time.Millisecond, h.Min())
+	assert.Equal(t, time.Second, h.Max())
+	assert.Equal(t, 500500*time.Microsecond, h.Mean())
+}
+
+func TestHistogramCountAtMost(t *testing.T) {
+	h := histogram.New()
+
+	// 1ms, 2ms, ..., 100ms
+	for i := 1; i <= 100; i++ {
+		h.Add(time.Duration(i) * time.Millisecond)
+	}
+
+	assert.Equal(t, uint64(0), h.CountAtMost(500*time.Microsecond))
+	assert.Equal(t, uint64(25), h.CountAtMost(25*time.Millisecond))
+	assert.Equal(t, uint64(100), h.CountAtMost(time.Second))
+	assert.Equal(t, 5050*time.Millisecond, h.Sum())
+}
+
+func TestHistogramEmpty(t *testing.T) {
+	h := histogram.New()
+
+	assert.Equal(t, time.Duration(0), h.Quantile(0.5))
+	assert.Equal(t, time.Duration(0), h.Mean())
+	assert.Equal(t, uint64(0), h.Count())
+}
+
+func TestHistogramMerge(t *testing.T) {
+	h1 := histogram.New()
+	h2 := histogram.New()
+	all := histogram.New()
+
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 1000; i++ {
+		v := time.Duration(r.Int63n(int64(time.Second)))
+		if i%2 == 0 {
+			h1.Add(v)
+		} else {
+			h2.Add(v)
+		}
+		all.Add(v)
+	}
+
+	h1.Merge(h2)
+
+	assert.Equal(t, all.Count(), h1.Count())
+	assert.Equal(t, all.Min(), h1.Min())
+	assert.Equal(t, all.Max(), h1.Max())
+	assert.Equal(t, all.Quantile(0.99), h1.Quantile(0.99))
+}
@@ -0,0 +1,64 @@
+// Package httpjson provides the helpers shared by the HTTP APIs: JSON responses and errors,
+// server-sent events and integer query parameters.
+package httpjson
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+)
+
+// Write writes the value as the JSON body of the response.
+func Write(w http.ResponseWriter, statusCode int, value interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	json.NewEncoder(w).Encode(value)
+}
+
+// WriteError writes the error as the JSON body of the response, e.g. {"error":"missing 'n' parameter"}.
+func WriteError(w http.ResponseWriter, statusCode int, err error) {
+	Write(w, statusCode, struct {
+		Error string `json:"error"`
+	}{Error: err.Error()})
+}
+
+// WriteEvent writes a server-sent event with the value encoded as JSON.
+func WriteEvent(w http.ResponseWriter, name string, value interface{}) {
+	data, _ := json.Marshal(value)
+	fmt.Fprintf(w, "event: %s\ndata: %s\n\n", name, data)
+}
+
+// IntParam returns the value of an integer query or form parameter.
+func IntParam(r *http.Request, name string) (int, error) {
+	value := r.FormValue(name)
+	if value == "" {
+		return 0, fmt.Errorf("missing '%s' parameter", name)
+	}
+
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("'%s' parameter needs to be an integer", name)
+	}
+
+	return n, nil
+}
+
+// OptionalIntParam returns the value of an integer query parameter greater or equal to 0,
+// or defaultValue if not set.
+func OptionalIntParam(r *http.Request, name string, defaultValue int) (int, error) {
+	if r.FormValue(name) == "" {
+		return defaultValue, nil
+	}
+
+	n, err := IntParam(r, name)
+	if err != nil {
+		return 0, err
+	}
+
+	if n < 0 {
+		return 0, fmt.Errorf("'%s' parameter needs to be greater or equal to 0", name)
+	}
+
+	return n, nil
+}
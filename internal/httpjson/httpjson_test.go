@@ -0,0 +1,78 @@
+package httpjson_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gustavooferreira/wcrawler/internal/httpjson"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOptionalIntParam(t *testing.T) {
+	tests := map[string]struct {
+		query         string
+		expectedValue int
+		expectedError string
+	}{
+		"not set": {
+			query:         "",
+			expectedValue: 10,
+		},
+		"set": {
+			query:         "?n=3",
+			expectedValue: 3,
+		},
+		"zero": {
+			query:         "?n=0",
+			expectedValue: 0,
+		},
+		"negative": {
+			query:         "?n=-1",
+			expectedError: "'n' parameter needs to be greater or equal to 0",
+		},
+		"not an integer": {
+			query:         "?n=abc",
+			expectedError: "'n' parameter needs to be an integer",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/"+test.query, nil)
+			n, err := httpjson.OptionalIntParam(r, "n", 10)
+
+			if test.expectedError != "" {
+				require.EqualError(t, err, test.expectedError)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, test.expectedValue, n)
+		})
+	}
+}
+
+func TestIntParamMissing(t *testing.T) {
+	r := httptest.NewRequest(http.MethodPost, "/", nil)
+	_, err := httpjson.IntParam(r, "n")
+	require.EqualError(t, err, "missing 'n' parameter")
+}
+
+func TestWriteError(t *testing.T) {
+	w := httptest.NewRecorder()
+	httpjson.WriteError(w, http.StatusBadRequest, fmt.Errorf("missing 'n' parameter"))
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+	assert.JSONEq(t, `{"error":"missing 'n' parameter"}`, w.Body.String())
+}
+
+func TestWriteEvent(t *testing.T) {
+	w := httptest.NewRecorder()
+	httpjson.WriteEvent(w, "fetch", map[string]int{"depth": 1})
+
+	assert.Equal(t, "event: fetch\ndata: {\"depth\":1}\n\n", w.Body.String())
+}
@@ -0,0 +1,136 @@
+// Package rate provides a time-based sliding window to compute event rates.
+package rate
+
+import "time"
+
+// Window counts values (e.g. requests or bytes) in fixed-size time buckets over a sliding window,
+// so rates reflect the recent activity instead of the whole run.
+// Window is not safe for concurrent use.
+type Window struct {
+	resolution time.Duration
+	// buckets is a ring buffer, the bucket for time t is at index (t / resolution) % len(buckets)
+	buckets []float64
+	// current is the number of the most recent bucket (t / resolution)
+	current int64
+	// first is the time the window started receiving values
+	first   time.Time
+	started bool
+}
+
+// New returns a new Window spanning size, with buckets of the given resolution.
+func New(size time.Duration, resolution time.Duration) *Window {
+	n := int(size / resolution)
+	if n < 2 {
+		n = 2
+	}
+
+	return &Window{resolution: resolution, buckets: make([]float64, n)}
+}
+
+// Start marks the time from which rates are computed.
+// Calling it is optional, otherwise rates are computed from the first value added.
+func (w *Window) Start(now time.Time) {
+	if !w.started {
+		w.started = true
+		w.first = now
+		w.current = w.bucketNumber(now)
+	}
+}
+
+// Add adds a value at the given time.
+func (w *Window) Add(now time.Time, value float64) {
+	w.Start(now)
+	w.advance(now)
+	w.buckets[w.index(w.current)] += value
+}
+
+// Rate returns the rate per second over the window, including the current (partial) bucket.
+// At the start, the rate is computed over the time elapsed since the window started.
+func (w *Window) Rate(now time.Time) float64 {
+	if !w.started {
+		return 0
+	}
+	w.advance(now)
+
+	windowStart := time.Unix(0, (w.current-int64(len(w.buckets))+1)*int64(w.resolution))
+	if w.first.After(windowStart) {
+		windowStart = w.first
+	}
+
+	elapsed := now.Sub(windowStart).Seconds()
+	if elapsed <= 0 {
+		return 0
+	}
+
+	total := 0.0
+	for _, v := range w.buckets {
+		total += v
+	}
+
+	return total / elapsed
+}
+
+// Series returns the rate per second of every complete bucket since the window started (oldest first).
+func (w *Window) Series(now time.Time) []float64 {
+	if !w.started {
+		return nil
+	}
+	w.advance(now)
+
+	oldest := w.current - int64(len(w.buckets)) + 1
+	if firstBucket := w.bucketNumber(w.first); firstBucket > oldest {
+		oldest = firstBucket
+	}
+
+	series := []float64{}
+	for b := oldest; b < w.current; b++ {
+		series = append(series, w.buckets[w.index(b)]/w.resolution.Seconds())
+	}
+
+	return series
+}
+
+// Smoothed returns the exponentially weighted moving average of the rate of the complete buckets,
+// where alpha (between 0 and 1) is the weight given to the most recent bucket.
+// Returns the plain rate if there are no complete buckets yet.
+func (w *Window) Smoothed(now time.Time, alpha float64) float64 {
+	series := w.Series(now)
+	if len(series) == 0 {
+		return w.Rate(now)
+	}
+
+	value := series[0]
+	for _, v := range series[1:] {
+		value = alpha*v + (1-alpha)*value
+	}
+
+	return value
+}
+
+// advance moves the window to the given time, clearing the buckets left behind.
+func (w *Window) advance(now time.Time) {
+	number := w.bucketNumber(now)
+	if number <= w.current {
+		return
+	}
+
+	if number-w.current >= int64(len(w.buckets)) {
+		for i := range w.buckets {
+			w.buckets[i] = 0
+		}
+	} else {
+		for b := w.current + 1; b <= number; b++ {
+			w.buckets[w.index(b)] = 0
+		}
+	}
+
+	w.current = number
+}
+
+func (w *Window) bucketNumber(t time.Time) int64 {
+	return t.UnixNano() / int64(w.resolution)
+}
+
+func (w *Window) index(number int64) int {
+	return int(number % int64(len(w.buckets)))
+}
@@ -0,0 +1,50 @@
+package rate_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gustavooferreira/wcrawler/internal/rate"
+	"github.com/stretchr/testify/assert"
+)
+
+var base = time.Date(2021, 1, 1, 10, 0, 0, 0, time.UTC)
+
+func TestWindowRate(t *testing.T) {
+	w := rate.New(10*time.Second, time.Second)
+	w.Start(base)
+
+	// 10 events per second during 5 seconds
+	for s := 0; s < 5; s++ {
+		for i := 0; i < 10; i++ {
+			w.Add(base.Add(time.Duration(s)*time.Second+time.Duration(i)*100*time.Millisecond), 1)
+		}
+	}
+
+	assert.InDelta(t, 10, w.Rate(base.Add(5*time.Second)), 0.001)
+	assert.Equal(t, []float64{10, 10, 10, 10, 10}, w.Series(base.Add(5*time.Second)))
+
+	// Old events leave the window (the window now spans from 1s to 10s, with 40 events in it)
+	assert.InDelta(t, 40.0/9, w.Rate(base.Add(10*time.Second)), 0.001)
+	assert.InDelta(t, 0, w.Rate(base.Add(30*time.Second)), 0.001)
+	assert.Equal(t, []float64{0, 0, 0, 0, 0, 0, 0, 0, 0}, w.Series(base.Add(30*time.Second)))
+}
+
+func TestWindowSmoothed(t *testing.T) {
+	w := rate.New(10*time.Second, time.Second)
+	w.Start(base)
+
+	w.Add(base, 10)
+	w.Add(base.Add(time.Second), 20)
+
+	// 10 -> 0.5*20 + 0.5*10
+	assert.InDelta(t, 15, w.Smoothed(base.Add(2*time.Second), 0.5), 0.001)
+}
+
+func TestWindowEmpty(t *testing.T) {
+	w := rate.New(10*time.Second, time.Second)
+
+	assert.Equal(t, 0.0, w.Rate(base))
+	assert.Equal(t, 0.0, w.Smoothed(base, 0.5))
+	assert.Nil(t, w.Series(base))
+}
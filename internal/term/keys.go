@@ -0,0 +1,45 @@
+// Package term reads the keys pressed on a terminal in raw mode (see golang.org/x/term to switch to it),
+// as needed by full-screen output.
+package term
+
+import "io"
+
+// KeyCtrlC is the key read when Ctrl-C is pressed on a terminal in raw mode.
+const KeyCtrlC byte = 3
+
+// ReadKeys reads keys from the reader (a terminal in raw mode) and calls handle with each of them,
+// until handle returns false or the reader returns an error.
+// Arrow keys, sent as escape sequences (e.g. "\x1b[A" for up), are passed as 'k', 'j', 'l' and 'h'
+// (up, down, right and left), like in vi.
+func ReadKeys(reader io.Reader, handle func(key byte) bool) {
+	buf := make([]byte, 64)
+
+	for {
+		n, err := reader.Read(buf)
+		for i := 0; i < n; i++ {
+			key := buf[i]
+
+			if key == 0x1b && i+2 < n && buf[i+1] == '[' {
+				switch buf[i+2] {
+				case 'A':
+					key = 'k'
+				case 'B':
+					key = 'j'
+				case 'C':
+					key = 'l'
+				case 'D':
+					key = 'h'
+				}
+				i += 2
+			}
+
+			if !handle(key) {
+				return
+			}
+		}
+
+		if err != nil {
+			return
+		}
+	}
+}
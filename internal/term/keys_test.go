@@ -0,0 +1,41 @@
+package term_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gustavooferreira/wcrawler/internal/term"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadKeys(t *testing.T) {
+	tests := map[string]struct {
+		input        string
+		expectedKeys string
+	}{
+		"plain keys": {
+			input:        "p+-s",
+			expectedKeys: "p+-s",
+		},
+		"arrow keys": {
+			input:        "\x1b[A\x1b[B\x1b[C\x1b[D",
+			expectedKeys: "kjlh",
+		},
+		"stops when asked to": {
+			input:        "ab\x1b[Bqcd",
+			expectedKeys: "abjq",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var keys []byte
+			term.ReadKeys(strings.NewReader(test.input), func(key byte) bool {
+				keys = append(keys, key)
+				return key != 'q'
+			})
+
+			assert.Equal(t, test.expectedKeys, string(keys))
+		})
+	}
+}
@@ -4,6 +4,8 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"sort"
+	"time"
 )
 
 // RecordManager keeps track of links visited and some metadata like depth level and its children.
@@ -11,6 +13,8 @@ type RecordManager struct {
 	// Keeps a table of Records. Key is the URL (scheme,authority,path,query)
 	Records    map[string]Record
 	IndexCount int
+	// Header is the metadata of the crawl, saved along with the records
+	Header CrawlHeader
 }
 
 // NewRecordManager returns a new Record Manager.
@@ -52,6 +56,7 @@ func (rm *RecordManager) AddRecord(entry RMEntry) {
 	if entry.ParentURL != "" {
 		if parentEntry, ok := rm.Records[entry.ParentURL]; ok {
 			parentEntry.Edges.Add(index)
+			setAnchorText(&parentEntry, index, entry.URL.AnchorText)
 			rm.Records[entry.ParentURL] = parentEntry
 		} else {
 			// we should have never landed here. being here, means there is a bug somewhere else.
@@ -82,10 +87,46 @@ func (rm *RecordManager) AddEdge(fromURL string, toURL string) error {
 	return nil
 }
 
+// AddAnchorText keeps the text of the link between two records, if not already present.
+func (rm *RecordManager) AddAnchorText(fromURL string, toURL string, text string) error {
+	toEntry, ok := rm.Records[toURL]
+	if !ok {
+		return fmt.Errorf("record not found")
+	}
+
+	fromEntry, ok := rm.Records[fromURL]
+	if !ok {
+		return fmt.Errorf("record not found")
+	}
+
+	setAnchorText(&fromEntry, toEntry.Index, text)
+	rm.Records[fromURL] = fromEntry
+	return nil
+}
+
+// setAnchorText sets the anchor text for the link pointing to the given index.
+// The first text found for a link is the one kept.
+func setAnchorText(r *Record, index int, text string) {
+	if text == "" {
+		return
+	}
+
+	if r.Anchors == nil {
+		r.Anchors = make(map[int]string)
+	}
+
+	if _, ok := r.Anchors[index]; !ok {
+		r.Anchors[index] = text
+	}
+}
+
 // Update updates entry in the table.
 func (rm *RecordManager) Update(rawURL string, statusCode int, err error) error {
 	if elem, ok := rm.Records[rawURL]; ok {
+		now := time.Now()
 		elem.StatusCode = statusCode
+		elem.ErrorCategory = ClassifyError(statusCode, err)
+		elem.FetchedAt = &now
 
 		if err != nil {
 			elem.ErrString = err.Error()
@@ -97,6 +138,22 @@ func (rm *RecordManager) Update(rawURL string, statusCode int, err error) error
 	return fmt.Errorf("record not found")
 }
 
+// SetResponseInfo stores the size, content type and timings of the response received for the record.
+func (rm *RecordManager) SetResponseInfo(rawURL string, info ResponseInfo) error {
+	elem, ok := rm.Records[rawURL]
+	if !ok {
+		return fmt.Errorf("record not found")
+	}
+
+	timings := info.Timings
+	elem.Size = info.BytesRead
+	elem.ContentType = info.ContentType
+	elem.Timings = &timings
+
+	rm.Records[rawURL] = elem
+	return nil
+}
+
 // Get returns a record from the Record Manager.
 func (rm *RecordManager) Get(rawURL string) (Record, bool) {
 	r, ok := rm.Records[rawURL]
@@ -113,22 +170,186 @@ func (rm *RecordManager) Dump() map[string]Record {
 	return rm.Records
 }
 
-// SaveToWriter dumps the records map into a Writer in JSON format.
+// Merge adds all records from another RecordManager into this one.
+// Records from the other RecordManager are re-indexed so they don't clash with existing ones,
+// and edges are remapped to the new indexes.
+// When a URL exists in both, edges are joined, the smallest depth is kept and the status code
+// of the most recent fetch wins (the other RecordManager wins if fetch times are unknown).
+// source identifies where the other records came from (e.g. the file name) and is kept in
+// the records' Sources, unless they already carry sources from a previous merge.
+func (rm *RecordManager) Merge(other *RecordManager, source string) {
+	next := rm.IndexCount
+	for _, r := range rm.Records {
+		if r.Index >= next {
+			next = r.Index + 1
+		}
+	}
+
+	// Sort the other records by index, so new indexes keep the same order
+	otherRecords := make([]Record, 0, len(other.Records))
+	for _, r := range other.Records {
+		otherRecords = append(otherRecords, r)
+	}
+	sort.Slice(otherRecords, func(i, j int) bool {
+		return otherRecords[i].Index < otherRecords[j].Index
+	})
+
+	// First pass: add records and work out the new indexes
+	indexMapping := make(map[int]int, len(otherRecords))
+
+	for _, o := range otherRecords {
+		sources := o.Sources
+		if len(sources) == 0 {
+			sources = []string{source}
+		}
+
+		r, ok := rm.Records[o.URL]
+		if !ok {
+			r = Record{
+				Index:         next,
+				InitPoint:     o.InitPoint,
+				URL:           o.URL,
+				Host:          o.Host,
+				Depth:         o.Depth,
+				Edges:         NewEdgesSet(),
+				StatusCode:    o.StatusCode,
+				ErrString:     o.ErrString,
+				ErrorCategory: o.ErrorCategory,
+				Size:          o.Size,
+				ContentType:   o.ContentType,
+				Timings:       o.Timings,
+				FetchedAt:     o.FetchedAt,
+				Scores:        o.Scores,
+			}
+			next++
+		} else {
+			if r.Edges == nil {
+				r.Edges = NewEdgesSet()
+			}
+
+			r.InitPoint = r.InitPoint || o.InitPoint
+
+			if o.Depth < r.Depth {
+				r.Depth = o.Depth
+			}
+
+			if fetchedMoreRecently(o, r) {
+				r.StatusCode = o.StatusCode
+				r.ErrString = o.ErrString
+				r.ErrorCategory = o.ErrorCategory
+				r.Size = o.Size
+				r.ContentType = o.ContentType
+				r.Timings = o.Timings
+				r.FetchedAt = o.FetchedAt
+			}
+		}
+
+		for _, s := range sources {
+			r.Sources = appendIfMissing(r.Sources, s)
+		}
+
+		indexMapping[o.Index] = r.Index
+		rm.Records[o.URL] = r
+	}
+
+	// Second pass: remap edges and anchors
+	for _, o := range otherRecords {
+		r := rm.Records[o.URL]
+
+		for edge := range o.Edges {
+			newIndex, ok := indexMapping[edge]
+			if !ok {
+				continue
+			}
+
+			r.Edges.Add(newIndex)
+			setAnchorText(&r, newIndex, o.Anchors[edge])
+		}
+
+		rm.Records[o.URL] = r
+	}
+
+	rm.IndexCount = next
+}
+
+// fetchedMoreRecently returns true if record a should take precedence over record b
+// when deciding which status code to keep.
+func fetchedMoreRecently(a Record, b Record) bool {
+	if !fetched(a) {
+		return false
+	}
+
+	if !fetched(b) {
+		return true
+	}
+
+	if a.FetchedAt != nil && b.FetchedAt != nil {
+		return !a.FetchedAt.Before(*b.FetchedAt)
+	}
+
+	return true
+}
+
+// appendIfMissing appends a string to the slice if it isn't already there.
+func appendIfMissing(slice []string, s string) []string {
+	for _, elem := range slice {
+		if elem == s {
+			return slice
+		}
+	}
+	return append(slice, s)
+}
+
+// URLsByIndex returns a map of the records' indexes to their URLs.
+func (rm *RecordManager) URLsByIndex() map[int]string {
+	urls := make(map[int]string, len(rm.Records))
+	for rawURL, r := range rm.Records {
+		urls[r.Index] = rawURL
+	}
+	return urls
+}
+
+// SaveToWriter writes the header and the records into a Writer as a CrawlFile in JSON format,
+// of the current schema version (see CrawlFileVersion).
 // Can pass a os.File, to write to a file.
 func (rm *RecordManager) SaveToWriter(w io.Writer, indent bool) error {
 	encoder := json.NewEncoder(w)
 	if indent {
 		encoder.SetIndent("", "    ")
 	}
-	err := encoder.Encode(rm.Records)
+	err := encoder.Encode(newCrawlFile(rm))
 	return err
 }
 
-// LoadFromReader reads the records from a Reader in JSON format.
+// LoadFromReader reads the header and the records from a Reader in JSON format, replacing the ones in the
+// RecordManager. Files of older schema versions are migrated, and the records are validated.
 // Can pass a os.File, to read from a file.
 func (rm *RecordManager) LoadFromReader(r io.Reader) error {
-	decoder := json.NewDecoder(r)
-	err := decoder.Decode(&rm.Records)
-	rm.IndexCount = len(rm.Records)
-	return err
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+
+	file, err := parseCrawlFile(data)
+	if err != nil {
+		return err
+	}
+
+	rm.Records = make(map[string]Record, len(file.Records))
+	rm.IndexCount = 0
+	rm.Header = file.Header
+
+	for _, record := range file.Records {
+		if record.Edges == nil {
+			record.Edges = NewEdgesSet()
+		}
+		rm.Records[record.URL] = record
+
+		// Indexes may have gaps (e.g. records removed by hand), new records go after the last one
+		if record.Index >= rm.IndexCount {
+			rm.IndexCount = record.Index + 1
+		}
+	}
+
+	return nil
 }
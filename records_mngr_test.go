@@ -3,6 +3,7 @@ package wcrawler_test
 import (
 	"bytes"
 	"testing"
+	"time"
 
 	"github.com/gustavooferreira/wcrawler"
 	"github.com/stretchr/testify/assert"
@@ -23,14 +24,15 @@ func TestAddRecords(t *testing.T) {
 }
 
 func TestSaveToWriter(t *testing.T) {
-	expected := `{"http://example1.com":{"index":0,"initPoint":true,"url":"http://example1.com",` +
+	expected := `{"schemaVersion":2,"header":{},"records":[` +
+		`{"index":0,"initPoint":true,"url":"http://example1.com",` +
 		`"host":"example1.com","depth":0,"edges":[1,2],"statusCode":200},` +
-		`"http://example1.com/about":{"index":1,"initPoint":false,"url":"http://example1.com/about",` +
+		`{"index":1,"initPoint":false,"url":"http://example1.com/about",` +
 		`"host":"example1.com","depth":1,"edges":[],"statusCode":200},` +
-		`"http://example1.com/main":{"index":2,"initPoint":false,"url":"http://example1.com/main",` +
+		`{"index":2,"initPoint":false,"url":"http://example1.com/main",` +
 		`"host":"example1.com","depth":1,"edges":[3],"statusCode":200},` +
-		`"http://example123.com/":{"index":3,"initPoint":false,"url":"http://example123.com/",` +
-		`"host":"example123.com","depth":2,"edges":[],"statusCode":200}}
+		`{"index":3,"initPoint":false,"url":"http://example123.com/",` +
+		`"host":"example123.com","depth":2,"edges":[],"statusCode":200}]}
 `
 
 	rm := wcrawler.NewRecordManager()
@@ -121,3 +123,125 @@ func addEntries(rm *wcrawler.RecordManager) {
 	}
 	rm.AddRecord(rmEntry4)
 }
+
+func TestAnchorText(t *testing.T) {
+	rm := wcrawler.NewRecordManager()
+	rm.AddRecord(wcrawler.RMEntry{URL: wcrawler.URLEntity{NetLoc: "example.com", Raw: "http://example.com"}})
+	rm.AddRecord(wcrawler.RMEntry{
+		ParentURL: "http://example.com",
+		URL:       wcrawler.URLEntity{NetLoc: "example.com", Raw: "http://example.com/about", AnchorText: "About"},
+		Depth:     1,
+	})
+
+	err := rm.AddAnchorText("http://example.com", "http://example.com/about", "Another text")
+	require.NoError(t, err)
+
+	err = rm.AddAnchorText("http://example.com", "http://example.com/unknown", "text")
+	require.Error(t, err)
+
+	value, ok := rm.Get("http://example.com")
+	require.Equal(t, true, ok)
+	assert.Equal(t, map[int]string{1: "About"}, value.Anchors)
+}
+
+func TestMerge(t *testing.T) {
+	rm1 := wcrawler.NewRecordManager()
+	addEntries(rm1)
+
+	// Second crawl starts somewhere else and links to a page known by the first crawl
+	rm2 := wcrawler.NewRecordManager()
+	rm2.AddRecord(wcrawler.RMEntry{
+		URL:        wcrawler.URLEntity{NetLoc: "example2.com", Raw: "http://example2.com"},
+		StatusCode: 200,
+	})
+	rm2.AddRecord(wcrawler.RMEntry{
+		ParentURL: "http://example2.com",
+		URL:       wcrawler.URLEntity{NetLoc: "example1.com", Raw: "http://example1.com/about"},
+		Depth:     1,
+	})
+	err := rm2.Update("http://example1.com/about", 404, nil)
+	require.NoError(t, err)
+
+	rm := wcrawler.NewRecordManager()
+	rm.Merge(rm1, "crawl1.json")
+	rm.Merge(rm2, "crawl2.json")
+
+	assert.Equal(t, 5, rm.Count())
+	assert.Equal(t, 5, rm.IndexCount)
+
+	// Indexes must be unique
+	urls := rm.URLsByIndex()
+	assert.Len(t, urls, 5)
+
+	value, ok := rm.Get("http://example2.com")
+	require.Equal(t, true, ok)
+	assert.Equal(t, 4, value.Index)
+	assert.Equal(t, true, value.InitPoint)
+	assert.Equal(t, []string{"crawl2.json"}, value.Sources)
+	assert.Equal(t, []int{1}, value.Edges.Dump())
+
+	// Most recent fetch wins
+	value, ok = rm.Get("http://example1.com/about")
+	require.Equal(t, true, ok)
+	assert.Equal(t, 1, value.Index)
+	assert.Equal(t, 404, value.StatusCode)
+	assert.Equal(t, []string{"crawl1.json", "crawl2.json"}, value.Sources)
+
+	value, ok = rm.Get("http://example1.com/main")
+	require.Equal(t, true, ok)
+	assert.Equal(t, []int{3}, value.Edges.Dump())
+	assert.Equal(t, "http://example123.com/", urls[3])
+}
+
+func TestSetResponseInfo(t *testing.T) {
+	rm := wcrawler.NewRecordManager()
+	rm.AddRecord(wcrawler.RMEntry{URL: wcrawler.URLEntity{NetLoc: "example.com", Raw: "http://example.com"}})
+
+	info := wcrawler.ResponseInfo{
+		BytesRead:   1024,
+		ContentType: "text/html",
+		Timings:     wcrawler.Timings{TTFB: 20 * time.Millisecond, Total: 30 * time.Millisecond},
+	}
+
+	err := rm.SetResponseInfo("http://example.com", info)
+	require.NoError(t, err)
+
+	err = rm.SetResponseInfo("http://example.com/unknown", info)
+	require.Error(t, err)
+
+	value, ok := rm.Get("http://example.com")
+	require.Equal(t, true, ok)
+	assert.Equal(t, int64(1024), value.Size)
+	assert.Equal(t, "text/html", value.ContentType)
+	require.NotNil(t, value.Timings)
+	assert.Equal(t, info.Timings, *value.Timings)
+}
+
+func TestUpdateErrorCategory(t *testing.T) {
+	rm := wcrawler.NewRecordManager()
+	rm.AddRecord(wcrawler.RMEntry{URL: wcrawler.URLEntity{NetLoc: "example.com", Raw: "http://example.com"}})
+	rm.AddRecord(wcrawler.RMEntry{ParentURL: "http://example.com",
+		URL: wcrawler.URLEntity{NetLoc: "example.com", Raw: "http://example.com/missing"}, Depth: 1})
+
+	err := rm.Update("http://example.com", 200, nil)
+	require.NoError(t, err)
+	err = rm.Update("http://example.com/missing", 404, nil)
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	err = rm.SaveToWriter(&buf, false)
+	require.NoError(t, err)
+	assert.Contains(t, buf.String(), `"errorCategory":"http_4xx"`)
+
+	loaded := wcrawler.NewRecordManager()
+	err = loaded.LoadFromReader(&buf)
+	require.NoError(t, err)
+
+	value, ok := loaded.Get("http://example.com/missing")
+	require.Equal(t, true, ok)
+	assert.Equal(t, wcrawler.ErrorCategory_HTTP4xx, value.ErrorCategory)
+
+	value, ok = loaded.Get("http://example.com")
+	require.Equal(t, true, ok)
+	assert.Equal(t, wcrawler.ErrorCategory_None, value.ErrorCategory)
+}
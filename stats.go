@@ -3,10 +3,13 @@ package wcrawler
 import (
 	"fmt"
 	"io"
+	"sort"
 	"strings"
 	"sync"
 	"time"
 
+	"github.com/gustavooferreira/wcrawler/internal/histogram"
+	"github.com/gustavooferreira/wcrawler/internal/rate"
 	"github.com/gustavooferreira/wcrawler/internal/ring"
 )
 
@@ -16,24 +19,113 @@ import (
 // I had originally implemented this using the "functional options pattern" and it
 // worked great. But it's not easy to abstract it away with an interface.
 
-// StatsCLIOutWriter keeps track of stats and writes to a writer up to date stats.
-type StatsCLIOutWriter struct {
+// StatsSnapshot represents the state of the stats at a given point in time.
+type StatsSnapshot struct {
+	State              string `json:"state"`
+	LinksCount         int    `json:"linksCount"`
+	LinksInQueue       int    `json:"linksInQueue"`
+	ErrorsCount        int    `json:"errorsCount"`
+	WorkersRunning     int    `json:"workersRunning"`
+	TotalWorkersCount  int    `json:"totalWorkersCount"`
+	TotalRequestsCount int    `json:"totalRequestsCount"`
+	Depth              int    `json:"depth"`
+	MaxDepthLevel      int    `json:"maxDepthLevel"`
+	// TTFB represents the time to first byte
+	TTFB LatencySummary `json:"ttfb"`
+	// TotalTime represents the time taken to download the whole page
+	TotalTime LatencySummary `json:"totalTime"`
+	// Phases represents the time spent in each phase of the requests
+	Phases     PhasesSummary `json:"phases"`
+	Throughput Throughput    `json:"throughput"`
+	// ErrorCategories counts the failed requests per category
+	ErrorCategories map[string]int       `json:"errorCategories"`
+	Hosts           map[string]HostStats `json:"hosts"`
+	LastErrors      []string             `json:"lastErrors"`
+}
 
-	// keep a reference to where to print stats
-	writer io.Writer
+// PhasesSummary represents the distribution of the time spent in each phase of the requests.
+// DNS lookup, TCP connect and TLS handshake only account for requests which opened a new connection.
+type PhasesSummary struct {
+	DNSLookup         LatencySummary `json:"dnsLookup"`
+	TCPConnect        LatencySummary `json:"tcpConnect"`
+	TLSHandshake      LatencySummary `json:"tlsHandshake"`
+	ContentTransfer   LatencySummary `json:"contentTransfer"`
+	ConnectionsReused int            `json:"connectionsReused"`
+}
 
-	// Read only vars
-	// --------------
-	// enables or disables the presentation of errors in the output
-	showErrorsFlag bool
-	// total
-	totalWorkersCount int
+// Throughput represents the rates (per second) over a sliding window of the last minute.
+type Throughput struct {
+	RequestsPerSecond float64 `json:"requestsPerSecond"`
+	// SmoothedRequestsPerSecond is an exponentially weighted moving average of the requests per second
+	SmoothedRequestsPerSecond float64 `json:"smoothedRequestsPerSecond"`
+	BytesPerSecond            float64 `json:"bytesPerSecond"`
+	ErrorsPerSecond           float64 `json:"errorsPerSecond"`
+	// ETA represents the estimated seconds left to process the links queued so far, at the smoothed rate.
+	// It doesn't account for the links still to be found, so it grows as pages are parsed and more links queued.
+	// It's only available when the crawl is bounded by depth (nil otherwise).
+	ETA *float64 `json:"eta"`
+}
+
+// LatencySummary represents the distribution of latency samples (in seconds).
+type LatencySummary struct {
+	Count uint64  `json:"count"`
+	Min   float64 `json:"min"`
+	Mean  float64 `json:"mean"`
+	Max   float64 `json:"max"`
+	P50   float64 `json:"p50"`
+	P90   float64 `json:"p90"`
+	P99   float64 `json:"p99"`
+	P999  float64 `json:"p999"`
+}
+
+// HostStats represents the stats for a single host.
+type HostStats struct {
+	Requests       int `json:"requests"`
+	WorkersRunning int `json:"workersRunning"`
+	// Errors counts the failed requests per category
+	Errors    map[string]int `json:"errors"`
+	TTFB      LatencySummary `json:"ttfb"`
+	TotalTime LatencySummary `json:"totalTime"`
+}
+
+// newLatencySummary returns the summary of the samples recorded in the histogram.
+func newLatencySummary(h *histogram.Histogram) LatencySummary {
+	return LatencySummary{
+		Count: h.Count(),
+		Min:   h.Min().Seconds(),
+		Mean:  h.Mean().Seconds(),
+		Max:   h.Max().Seconds(),
+		P50:   h.Quantile(0.5).Seconds(),
+		P90:   h.Quantile(0.9).Seconds(),
+		P99:   h.Quantile(0.99).Seconds(),
+		P999:  h.Quantile(0.999).Seconds(),
+	}
+}
+
+// hostLatency keeps track of the latency samples and errors for a single host.
+type hostLatency struct {
+	requests int
+	bytes    int64
+	// number of workers making a request to the host
+	workersRunning int
+	errors         map[ErrorCategory]int
+	ttfb           *histogram.Histogram
+	totalTime      *histogram.Histogram
+}
+
+// statsCounters keeps track of the counters common to all StatsManager implementations.
+// It implements all methods of the StatsManager interface except RunOutputFlusher,
+// and is meant to be embedded in StatsManager implementations.
+type statsCounters struct {
 	// max depth level provided by user
 	maxDepthLevel int
 
 	// mu protects access to the fields below
 	mu sync.Mutex
 
+	// total number of workers (can be changed while the crawler runs)
+	totalWorkersCount int
+
 	// Crawler state
 	state AppState
 
@@ -46,141 +138,440 @@ type StatsCLIOutWriter struct {
 	workersRunning int
 	// number of HTTP requests made
 	totalRequestsCount int
+	// number of requests per status class (e.g. '2xx', or 'error' if no response was received)
+	statusClasses map[string]int
+	// number of bytes downloaded
+	bytesDownloaded int64
 	// current level of depth
 	depth int
 
-	// latency
-	lMin float64
-	lMax float64
-	// Use these two numbers to compute average
-	lAvgSum   float64
-	lAvgCount float64
-
-	// Requests per second
-	rps int
+	// latency (time to first byte)
+	ttfb *histogram.Histogram
+	// time taken to download the whole page
+	totalTime *histogram.Histogram
+	// latency per host
+	hosts map[string]*hostLatency
+
+	// time spent in each phase of the requests
+	dnsLookup         *histogram.Histogram
+	tcpConnect        *histogram.Histogram
+	tlsHandshake      *histogram.Histogram
+	contentTransfer   *histogram.Histogram
+	connectionsReused int
+
+	// throughput over a sliding window, frozen once the crawler finishes
+	finishedAt     time.Time
+	requestsWindow *rate.Window
+	bytesWindow    *rate.Window
+	errorsWindow   *rate.Window
+
+	// number of failed requests per category
+	errorCategories map[ErrorCategory]int
 
 	// List of errors that happen during crawling
 	errorsList ring.Buffer
+	// List of the last requests made
+	fetchesList ring.Buffer
 }
 
-// NewStatsCLIOutWriter returns a new StatsCLIOutWriter.
-func NewStatsCLIOutWriter(writer io.Writer, showErrors bool, totalWorkersCount int, depth int) *StatsCLIOutWriter {
-	sm := StatsCLIOutWriter{
-		writer:            writer,
-		showErrorsFlag:    showErrors,
-		state:             AppState_IDLE,
-		totalWorkersCount: totalWorkersCount,
-		maxDepthLevel:     depth,
-		errorsList:        ring.New(10)}
-	return &sm
+// init initializes the counters. Must be called before using any of the other methods.
+func (sc *statsCounters) init(totalWorkersCount int, depth int) {
+	sc.state = AppState_IDLE
+	sc.totalWorkersCount = totalWorkersCount
+	sc.maxDepthLevel = depth
+	sc.errorsList = ring.New(10)
+	sc.fetchesList = ring.New(maxFetchEntries)
+	sc.ttfb = histogram.New()
+	sc.totalTime = histogram.New()
+	sc.hosts = map[string]*hostLatency{}
+	sc.errorCategories = map[ErrorCategory]int{}
+	sc.statusClasses = map[string]int{}
+	sc.dnsLookup = histogram.New()
+	sc.tcpConnect = histogram.New()
+	sc.tlsHandshake = histogram.New()
+	sc.contentTransfer = histogram.New()
+	sc.requestsWindow = rate.New(throughputWindowSize, time.Second)
+	sc.bytesWindow = rate.New(throughputWindowSize, time.Second)
+	sc.errorsWindow = rate.New(throughputWindowSize, time.Second)
 }
 
-func (sm *StatsCLIOutWriter) SetAppState(state AppState) {
-	sm.mu.Lock()
-	defer sm.mu.Unlock()
-	sm.state = state
+func (sc *statsCounters) SetAppState(state AppState) {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	sc.state = state
+
+	switch state {
+	case AppState_Running:
+		now := time.Now()
+		sc.requestsWindow.Start(now)
+		sc.bytesWindow.Start(now)
+		sc.errorsWindow.Start(now)
+	case AppState_Finished:
+		sc.finishedAt = time.Now()
+	}
 }
 
-func (sm *StatsCLIOutWriter) SetLinksInQueue(value int) {
-	sm.mu.Lock()
-	defer sm.mu.Unlock()
-	sm.linksInQueue = value
+func (sc *statsCounters) SetLinksInQueue(value int) {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	sc.linksInQueue = value
 }
 
-func (sm *StatsCLIOutWriter) IncDecLinksInQueue(value int) {
-	sm.mu.Lock()
-	defer sm.mu.Unlock()
-	sm.linksInQueue += value
+func (sc *statsCounters) IncDecLinksInQueue(value int) {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	sc.linksInQueue += value
 }
 
-func (sm *StatsCLIOutWriter) SetLinksCount(value int) {
-	sm.mu.Lock()
-	defer sm.mu.Unlock()
-	sm.linksInQueue = value
+func (sc *statsCounters) SetLinksCount(value int) {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	sc.linksCount = value
 }
 
-func (sm *StatsCLIOutWriter) IncDecLinksCount(value int) {
-	sm.mu.Lock()
-	defer sm.mu.Unlock()
-	sm.linksCount += value
+func (sc *statsCounters) IncDecLinksCount(value int) {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	sc.linksCount += value
 }
 
-func (sm *StatsCLIOutWriter) SetErrorsCount(value int) {
-	sm.mu.Lock()
-	defer sm.mu.Unlock()
-	sm.errorCounts = value
+func (sc *statsCounters) SetErrorsCount(value int) {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	sc.errorCounts = value
 }
 
-func (sm *StatsCLIOutWriter) IncDecErrorsCount(value int) {
-	sm.mu.Lock()
-	defer sm.mu.Unlock()
-	sm.errorCounts += value
+func (sc *statsCounters) IncDecErrorsCount(value int) {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	sc.errorCounts += value
 }
 
-func (sm *StatsCLIOutWriter) SetWorkersRunning(value int) {
-	sm.mu.Lock()
-	defer sm.mu.Unlock()
-	sm.workersRunning = value
+func (sc *statsCounters) SetWorkersRunning(value int) {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	sc.workersRunning = value
 }
 
-func (sm *StatsCLIOutWriter) IncDecWorkersRunning(value int) {
-	sm.mu.Lock()
-	defer sm.mu.Unlock()
-	sm.workersRunning += value
+func (sc *statsCounters) IncDecWorkersRunning(value int) {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	sc.workersRunning += value
 }
 
-func (sm *StatsCLIOutWriter) SetTotalRequestsCount(value int) {
-	sm.mu.Lock()
-	defer sm.mu.Unlock()
-	sm.totalRequestsCount = value
+func (sc *statsCounters) SetTotalWorkersCount(value int) {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	sc.totalWorkersCount = value
 }
 
-func (sm *StatsCLIOutWriter) IncDecTotalRequestsCount(value int) {
-	sm.mu.Lock()
-	defer sm.mu.Unlock()
-	sm.totalRequestsCount += value
+func (sc *statsCounters) SetMaxDepthLevel(value int) {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	sc.maxDepthLevel = value
 }
 
-func (sm *StatsCLIOutWriter) SetDepth(value int) {
-	sm.mu.Lock()
-	defer sm.mu.Unlock()
-	sm.depth = value
+func (sc *statsCounters) IncDecHostWorkersRunning(host string, value int) {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	sc.hostLatency(host).workersRunning += value
 }
 
-func (sm *StatsCLIOutWriter) IncDecDepth(value int) {
-	sm.mu.Lock()
-	defer sm.mu.Unlock()
-	sm.depth += value
+func (sc *statsCounters) SetTotalRequestsCount(value int) {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	sc.totalRequestsCount = value
 }
 
-func (sm *StatsCLIOutWriter) AddLatencySample(value time.Duration) {
-	sm.mu.Lock()
-	defer sm.mu.Unlock()
+func (sc *statsCounters) IncDecTotalRequestsCount(value int) {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	sc.totalRequestsCount += value
+}
 
-	valueF := value.Seconds()
-	if sm.lAvgCount == 0 {
-		sm.lMax = valueF
-		sm.lMin = valueF
-		sm.lAvgCount = 1
-		sm.lAvgSum = valueF
-	} else {
-		if valueF > sm.lMax {
-			sm.lMax = valueF
+func (sc *statsCounters) SetDepth(value int) {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	sc.depth = value
+}
+
+func (sc *statsCounters) IncDecDepth(value int) {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	sc.depth += value
+}
+
+func (sc *statsCounters) AddLatencySample(value time.Duration) {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	sc.ttfb.Add(value)
+}
+
+func (sc *statsCounters) AddErrorEntry(value string) {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	sc.errorsList.Add(value)
+}
+
+func (sc *statsCounters) AddFetchEvent(event FetchEvent) {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	sc.fetchesList.Add(event.String())
+}
+
+// AddResponseSample keeps track of the time spent in each phase of the request, the status classes,
+// the bytes downloaded, the failures per category and the latency per host.
+func (sc *statsCounters) AddResponseSample(host string, statusCode int, info ResponseInfo, err error) {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+
+	category := ClassifyError(statusCode, err)
+
+	now := time.Now()
+	sc.requestsWindow.Add(now, 1)
+	sc.bytesWindow.Add(now, float64(info.BytesRead))
+	if category != ErrorCategory_None {
+		sc.errorsWindow.Add(now, 1)
+		sc.errorCategories[category]++
+	}
+
+	class := "error"
+	if err == nil {
+		class = fmt.Sprintf("%dxx", statusCode/100)
+	}
+	sc.statusClasses[class]++
+	sc.bytesDownloaded += info.BytesRead
+
+	hl := sc.hostLatency(host)
+	hl.requests++
+	hl.bytes += info.BytesRead
+	if category != ErrorCategory_None {
+		hl.errors[category]++
+	}
+
+	// Failed requests got no response, so only their time to fail is known
+	timings := info.Timings
+	if err != nil || timings.TTFB <= 0 {
+		return
+	}
+
+	sc.totalTime.Add(timings.Total)
+	hl.ttfb.Add(timings.TTFB)
+	hl.totalTime.Add(timings.Total)
+
+	if timings.ConnReused {
+		sc.connectionsReused++
+	}
+
+	if timings.DNSLookup != 0 {
+		sc.dnsLookup.Add(timings.DNSLookup)
+	}
+
+	if timings.TCPConnect != 0 {
+		sc.tcpConnect.Add(timings.TCPConnect)
+	}
+
+	if timings.TLSHandshake != 0 {
+		sc.tlsHandshake.Add(timings.TLSHandshake)
+	}
+
+	sc.contentTransfer.Add(timings.ContentTransfer)
+}
+
+// Snapshot returns the current state of the stats.
+func (sc *statsCounters) Snapshot() StatsSnapshot {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	return sc.snapshot()
+}
+
+// snapshot returns the current state of the stats. Lock must be held by the caller.
+func (sc *statsCounters) snapshot() StatsSnapshot {
+	s := StatsSnapshot{
+		State:              sc.state.String(),
+		LinksCount:         sc.linksCount,
+		LinksInQueue:       sc.linksInQueue,
+		ErrorsCount:        sc.errorCounts,
+		WorkersRunning:     sc.workersRunning,
+		TotalWorkersCount:  sc.totalWorkersCount,
+		TotalRequestsCount: sc.totalRequestsCount,
+		Depth:              sc.depth,
+		MaxDepthLevel:      sc.maxDepthLevel,
+		TTFB:               newLatencySummary(sc.ttfb),
+		TotalTime:          newLatencySummary(sc.totalTime),
+		Phases: PhasesSummary{
+			DNSLookup:         newLatencySummary(sc.dnsLookup),
+			TCPConnect:        newLatencySummary(sc.tcpConnect),
+			TLSHandshake:      newLatencySummary(sc.tlsHandshake),
+			ContentTransfer:   newLatencySummary(sc.contentTransfer),
+			ConnectionsReused: sc.connectionsReused,
+		},
+		Throughput:      sc.throughput(time.Now()),
+		ErrorCategories: categoryCounts(sc.errorCategories),
+		Hosts:           make(map[string]HostStats, len(sc.hosts)),
+		LastErrors:      sc.errorsList.ReadAll(),
+	}
+
+	for host, hl := range sc.hosts {
+		s.Hosts[host] = HostStats{
+			Requests:       hl.requests,
+			WorkersRunning: hl.workersRunning,
+			Errors:         categoryCounts(hl.errors),
+			TTFB:           newLatencySummary(hl.ttfb),
+			TotalTime:      newLatencySummary(hl.totalTime),
 		}
+	}
 
-		if valueF < sm.lMin {
-			sm.lMin = valueF
+	if s.LastErrors == nil {
+		s.LastErrors = []string{}
+	}
+
+	return s
+}
+
+// clock returns the time the stats are read at: now, or the time the crawler finished once it has,
+// so the sliding windows don't move on after the crawl. Lock must be held by the caller.
+func (sc *statsCounters) clock(now time.Time) time.Time {
+	if sc.state == AppState_Finished && !sc.finishedAt.IsZero() {
+		return sc.finishedAt
+	}
+	return now
+}
+
+// throughput returns the rates over the sliding window. Lock must be held by the caller.
+// Once the crawler finishes, the rates are the ones at the time it finished.
+func (sc *statsCounters) throughput(now time.Time) Throughput {
+	now = sc.clock(now)
+
+	t := Throughput{
+		RequestsPerSecond:         sc.requestsWindow.Rate(now),
+		SmoothedRequestsPerSecond: sc.requestsWindow.Smoothed(now, smoothingFactor),
+		BytesPerSecond:            sc.bytesWindow.Rate(now),
+		ErrorsPerSecond:           sc.errorsWindow.Rate(now),
+	}
+
+	// Without a max depth, the number of links left is unbounded
+	if sc.maxDepthLevel != 0 {
+		eta := 0.0
+		if sc.state != AppState_Finished && sc.linksInQueue != 0 {
+			if t.SmoothedRequestsPerSecond == 0 {
+				return t
+			}
+			eta = float64(sc.linksInQueue) / t.SmoothedRequestsPerSecond
 		}
+		t.ETA = &eta
+	}
 
-		sm.lAvgCount++
-		sm.lAvgSum += valueF
+	return t
+}
+
+// hostLatency returns the stats of a host, creating them if needed. Lock must be held by the caller.
+func (sc *statsCounters) hostLatency(host string) *hostLatency {
+	hl, ok := sc.hosts[host]
+	if !ok {
+		hl = &hostLatency{errors: map[ErrorCategory]int{}, ttfb: histogram.New(), totalTime: histogram.New()}
+		sc.hosts[host] = hl
+	}
+
+	return hl
+}
+
+// topErrorCategories returns up to n categories with the most errors. Lock must be held by the caller.
+func (sc *statsCounters) topErrorCategories(n int) []ErrorCategory {
+	categories := make([]ErrorCategory, 0, len(sc.errorCategories))
+	for category := range sc.errorCategories {
+		categories = append(categories, category)
+	}
+
+	sort.Slice(categories, func(i, j int) bool {
+		if sc.errorCategories[categories[i]] != sc.errorCategories[categories[j]] {
+			return sc.errorCategories[categories[i]] > sc.errorCategories[categories[j]]
+		}
+		return categories[i] < categories[j]
+	})
+
+	if len(categories) > n {
+		categories = categories[:n]
+	}
+
+	return categories
+}
+
+// categoryCounts returns the counts keyed by the name of the category.
+func categoryCounts(counts map[ErrorCategory]int) map[string]int {
+	m := make(map[string]int, len(counts))
+	for category, count := range counts {
+		m[category.String()] = count
+	}
+
+	return m
+}
+
+// busiestHosts returns up to n hosts with the most requests. Lock must be held by the caller.
+func (sc *statsCounters) busiestHosts(n int) []string {
+	hosts := make([]string, 0, len(sc.hosts))
+	for host := range sc.hosts {
+		hosts = append(hosts, host)
+	}
+
+	sort.Slice(hosts, func(i, j int) bool {
+		if sc.hosts[hosts[i]].requests != sc.hosts[hosts[j]].requests {
+			return sc.hosts[hosts[i]].requests > sc.hosts[hosts[j]].requests
+		}
+		return hosts[i] < hosts[j]
+	})
+
+	if len(hosts) > n {
+		hosts = hosts[:n]
 	}
+
+	return hosts
+}
+
+// finished returns true if the crawler has finished.
+func (sc *statsCounters) finished() bool {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	return sc.state == AppState_Finished
+}
+
+// maxHostsDisplayed is the max number of hosts shown in the live stats.
+const maxHostsDisplayed = 5
+
+// maxFetchEntries is the number of requests kept in the list of the last requests made.
+const maxFetchEntries = 50
+
+// maxErrorCategoriesDisplayed is the max number of error categories shown in the live stats.
+const maxErrorCategoriesDisplayed = 4
+
+const (
+	// throughputWindowSize is the time span of the sliding window used to compute the rates.
+	throughputWindowSize = time.Minute
+	// smoothingFactor is the weight given to the most recent second when smoothing the requests per second.
+	smoothingFactor = 0.3
+	// sparklineLength is the number of seconds shown in the throughput sparkline.
+	sparklineLength = 30
+)
+
+// StatsCLIOutWriter keeps track of stats and writes to a writer up to date stats.
+type StatsCLIOutWriter struct {
+	statsCounters
+
+	// keep a reference to where to print stats
+	writer io.Writer
+
+	// enables or disables the presentation of errors in the output
+	showErrorsFlag bool
 }
 
-func (sm *StatsCLIOutWriter) AddErrorEntry(value string) {
-	sm.mu.Lock()
-	defer sm.mu.Unlock()
-	sm.errorsList.Add(value)
+// NewStatsCLIOutWriter returns a new StatsCLIOutWriter.
+func NewStatsCLIOutWriter(writer io.Writer, showErrors bool, totalWorkersCount int, depth int) *StatsCLIOutWriter {
+	sm := StatsCLIOutWriter{
+		writer:         writer,
+		showErrorsFlag: showErrors,
+	}
+	sm.init(totalWorkersCount, depth)
+	return &sm
 }
 
 // This functions writes the updated stats to an io.Writer
@@ -191,22 +582,21 @@ func (sm *StatsCLIOutWriter) RunOutputFlusher() {
 		"Links in Cache: %10d     Depth:       (%3d/%3d)\n" +
 		"Links in Queue: %10d     Workers:   (%4d/%4d)\n" +
 		"Total Req Count: %9d     Errors: %5d (%5.2f%%)\n" +
-		"Requests/s: %14d\n" +
+		"Requests/s: %8.1f (smoothed %8.1f)   Errors/s: %6.1f\n" +
+		"Download: %12s/s    ETA (queued): %10s\n" +
+		"Throughput (last %ds): %s\n" +
 		"Latency --------------- (in  seconds) ---------------\n" +
-		"Min: %6.3f    -     Avg: %6.3f     -    Max: %6.3f\n"
+		"Min: %6.3f    -     Avg: %6.3f     -    Max: %6.3f\n" +
+		"TTFB    p50: %6.3f  p90: %6.3f  p99: %6.3f  p999: %6.3f\n" +
+		"Total   p50: %6.3f  p90: %6.3f  p99: %6.3f  p999: %6.3f\n" +
+		"Avg DNS: %5.3f  TCP: %5.3f  TLS: %5.3f  Xfer: %5.3f\n" +
+		"Connections reused: %5.1f%%\n"
 
 	errorsStr := "Last 10 errors max ----------------------------------\n"
+	hostsStr := "Busiest hosts ------ (total time in seconds) --------\n"
 
 	// If zero samples, don't display latency
 
-	// Setup counter (up to 5 times) since we wait 200 milliseconds,
-	// when we get to 5 it means it's time to look at the totalrequest count
-	// and subtract from the previous value
-
-	previousTotalRequestCount := 0
-	cyclesCount := 0
-	var rps int = 0
-
 	for {
 		sm.mu.Lock()
 
@@ -215,12 +605,16 @@ func (sm *StatsCLIOutWriter) RunOutputFlusher() {
 			errorsPerc = 100 * float64(sm.errorCounts) / float64(sm.totalRequestsCount)
 		}
 
-		// requests per second
-		// This is a rough estimation
-		if cyclesCount == 5 {
-			cyclesCount = 0
-			rps = sm.totalRequestsCount - previousTotalRequestCount
-			previousTotalRequestCount = sm.totalRequestsCount
+		reusedPerc := 0.0
+		if sm.totalTime.Count() != 0 {
+			reusedPerc = 100 * float64(sm.connectionsReused) / float64(sm.totalTime.Count())
+		}
+
+		now := sm.clock(time.Now())
+		throughput := sm.throughput(now)
+		series := sm.requestsWindow.Series(now)
+		if len(series) > sparklineLength {
+			series = series[len(series)-sparklineLength:]
 		}
 
 		// truncate error messages to 50 chars
@@ -240,7 +634,33 @@ func (sm *StatsCLIOutWriter) RunOutputFlusher() {
 
 		fmt.Fprintf(&statsBuf, fmtStr, sm.state, sm.linksCount, sm.depth, sm.maxDepthLevel,
 			sm.linksInQueue, sm.workersRunning, sm.totalWorkersCount, sm.totalRequestsCount,
-			sm.errorCounts, errorsPerc, rps, sm.lMin, sm.lAvgSum/sm.lAvgCount, sm.lMax)
+			sm.errorCounts, errorsPerc, throughput.RequestsPerSecond, throughput.SmoothedRequestsPerSecond,
+			throughput.ErrorsPerSecond, formatBytes(throughput.BytesPerSecond), formatETA(throughput.ETA),
+			sparklineLength, sparkline(series), sm.ttfb.Min().Seconds(), sm.ttfb.Mean().Seconds(), sm.ttfb.Max().Seconds(),
+			sm.ttfb.Quantile(0.5).Seconds(), sm.ttfb.Quantile(0.9).Seconds(),
+			sm.ttfb.Quantile(0.99).Seconds(), sm.ttfb.Quantile(0.999).Seconds(),
+			sm.totalTime.Quantile(0.5).Seconds(), sm.totalTime.Quantile(0.9).Seconds(),
+			sm.totalTime.Quantile(0.99).Seconds(), sm.totalTime.Quantile(0.999).Seconds(),
+			sm.dnsLookup.Mean().Seconds(), sm.tcpConnect.Mean().Seconds(),
+			sm.tlsHandshake.Mean().Seconds(), sm.contentTransfer.Mean().Seconds(), reusedPerc)
+
+		if len(sm.hosts) != 0 {
+			fmt.Fprintf(&statsBuf, hostsStr)
+
+			for _, host := range sm.busiestHosts(maxHostsDisplayed) {
+				hl := sm.hosts[host]
+				fmt.Fprintf(&statsBuf, "%-22s %5d  p50: %6.3f  p99: %6.3f\n", truncate(host, 22), hl.requests,
+					hl.totalTime.Quantile(0.5).Seconds(), hl.totalTime.Quantile(0.99).Seconds())
+			}
+		}
+
+		if len(sm.errorCategories) != 0 {
+			fmt.Fprintf(&statsBuf, "Top errors:")
+			for _, category := range sm.topErrorCategories(maxErrorCategoriesDisplayed) {
+				fmt.Fprintf(&statsBuf, "  %s: %d", category, sm.errorCategories[category])
+			}
+			fmt.Fprintln(&statsBuf)
+		}
 
 		if sm.showErrorsFlag {
 			if sm.errorsList.Len() != 0 {
@@ -253,14 +673,69 @@ func (sm *StatsCLIOutWriter) RunOutputFlusher() {
 		}
 
 		fmt.Fprint(sm.writer, statsBuf.String())
+		state := sm.state
 		sm.mu.Unlock()
 
 		// Only stop when AppState == Finished!
-		if sm.state == AppState_Finished {
+		if state == AppState_Finished {
 			break
 		}
 
 		time.Sleep(time.Millisecond * 200)
-		cyclesCount++
 	}
 }
+
+// sparkline returns a string representing the values as bars of different heights.
+func sparkline(values []float64) string {
+	bars := []rune("▁▂▃▄▅▆▇█")
+
+	max := 0.0
+	for _, v := range values {
+		if v > max {
+			max = v
+		}
+	}
+
+	var b strings.Builder
+	for _, v := range values {
+		index := 0
+		if max > 0 {
+			index = int(v / max * float64(len(bars)-1))
+		}
+		b.WriteRune(bars[index])
+	}
+
+	return b.String()
+}
+
+// formatBytes returns a human readable representation of a number of bytes.
+func formatBytes(bytes float64) string {
+	units := []string{"B", "KB", "MB", "GB", "TB"}
+
+	i := 0
+	for bytes >= 1024 && i < len(units)-1 {
+		bytes /= 1024
+		i++
+	}
+
+	return fmt.Sprintf("%.1f %s", bytes, units[i])
+}
+
+// formatETA returns a human readable representation of the estimated seconds left.
+func formatETA(eta *float64) string {
+	if eta == nil {
+		return "unknown"
+	}
+
+	return time.Duration(*eta * float64(time.Second)).Round(time.Second).String()
+}
+
+// truncate truncates a string to n characters, adding an ellipsis if needed.
+func truncate(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+
+	return string(runes[:n-3]) + "..."
+}
@@ -0,0 +1,225 @@
+package wcrawler
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gustavooferreira/wcrawler/internal/httpjson"
+	"github.com/gustavooferreira/wcrawler/internal/ring"
+)
+
+const (
+	// maxAPIErrors is the number of errors kept to be served by the StatsAPI
+	maxAPIErrors = 100
+	// defaultFrontierSample is the number of links listed in the frontier when not set in the request
+	defaultFrontierSample = 20
+	// eventsBufferSize is the number of events buffered per events stream. Events are dropped for slow clients.
+	eventsBufferSize = 100
+)
+
+// StatsAPI keeps track of stats and serves them, along with the state of the crawl, as a JSON API.
+// It implements http.Handler:
+//
+//	GET /stats           current stats
+//	GET /errors?n=10     last errors (up to 100)
+//	GET /frontier?n=20   number of links waiting to be requested and a sample of them
+//	GET /records         snapshot of the records collected so far (same format as the output file)
+//	GET /events          server-sent events stream of the requests made ('fetch' events),
+//	                     ending with a 'finished' event carrying the final stats
+type StatsAPI struct {
+	statsCounters
+
+	mux *http.ServeMux
+
+	// the fields below are protected by the mutex in statsCounters
+	inspector Inspector
+	// subscribers to the fetch events
+	subscribers map[chan FetchEvent]struct{}
+	// closed is set when the crawler finishes and the events streams are closed
+	closed bool
+}
+
+// NewStatsAPI returns a new StatsAPI.
+func NewStatsAPI(totalWorkersCount int, depth int) *StatsAPI {
+	sm := StatsAPI{mux: http.NewServeMux(), subscribers: map[chan FetchEvent]struct{}{}}
+	sm.init(totalWorkersCount, depth)
+	sm.errorsList = ring.New(maxAPIErrors)
+
+	sm.mux.HandleFunc("/stats", sm.handleStats)
+	sm.mux.HandleFunc("/errors", sm.handleErrors)
+	sm.mux.HandleFunc("/frontier", sm.handleFrontier)
+	sm.mux.HandleFunc("/records", sm.handleRecords)
+	sm.mux.HandleFunc("/events", sm.handleEvents)
+
+	return &sm
+}
+
+// SetInspector sets the Inspector used to serve the frontier and the records.
+func (sm *StatsAPI) SetInspector(inspector Inspector) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	sm.inspector = inspector
+}
+
+// AddFetchEvent keeps track of the request made and sends it to the events streams.
+func (sm *StatsAPI) AddFetchEvent(event FetchEvent) {
+	sm.statsCounters.AddFetchEvent(event)
+
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	for events := range sm.subscribers {
+		select {
+		case events <- event:
+		default:
+			// The client isn't keeping up
+		}
+	}
+}
+
+// RunOutputFlusher waits for the crawler to finish and closes the events streams.
+// Run this in a goroutine
+func (sm *StatsAPI) RunOutputFlusher() {
+	for !sm.finished() {
+		time.Sleep(time.Millisecond * 200)
+	}
+
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	sm.closed = true
+	for events := range sm.subscribers {
+		close(events)
+		delete(sm.subscribers, events)
+	}
+}
+
+// ServeHTTP dispatches the request to the endpoint for its path.
+func (sm *StatsAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		httpjson.WriteError(w, http.StatusMethodNotAllowed, fmt.Errorf("method %s not allowed", r.Method))
+		return
+	}
+
+	sm.mux.ServeHTTP(w, r)
+}
+
+func (sm *StatsAPI) handleStats(w http.ResponseWriter, r *http.Request) {
+	httpjson.Write(w, http.StatusOK, sm.Snapshot())
+}
+
+func (sm *StatsAPI) handleErrors(w http.ResponseWriter, r *http.Request) {
+	n, err := httpjson.OptionalIntParam(r, "n", maxAPIErrors)
+	if err != nil {
+		httpjson.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	sm.mu.Lock()
+	errors := sm.errorsList.ReadAll()
+	sm.mu.Unlock()
+
+	if n >= 0 && n < len(errors) {
+		errors = errors[len(errors)-n:]
+	}
+
+	httpjson.Write(w, http.StatusOK, errors)
+}
+
+func (sm *StatsAPI) handleFrontier(w http.ResponseWriter, r *http.Request) {
+	n, err := httpjson.OptionalIntParam(r, "n", defaultFrontierSample)
+	if err != nil {
+		httpjson.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	inspector, ok := sm.getInspector(w)
+	if !ok {
+		return
+	}
+
+	httpjson.Write(w, http.StatusOK, inspector.Frontier(n))
+}
+
+func (sm *StatsAPI) handleRecords(w http.ResponseWriter, r *http.Request) {
+	inspector, ok := sm.getInspector(w)
+	if !ok {
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	inspector.WriteRecords(w)
+}
+
+func (sm *StatsAPI) handleEvents(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		httpjson.WriteError(w, http.StatusInternalServerError, fmt.Errorf("streaming not supported"))
+		return
+	}
+
+	events := sm.subscribe()
+	defer sm.unsubscribe(events)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+
+	// Let the client know the stream is ready
+	fmt.Fprint(w, ": connected\n\n")
+	flusher.Flush()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case event, ok := <-events:
+			if !ok {
+				httpjson.WriteEvent(w, "finished", sm.Snapshot())
+				flusher.Flush()
+				return
+			}
+
+			httpjson.WriteEvent(w, "fetch", event)
+			flusher.Flush()
+		}
+	}
+}
+
+// subscribe returns a channel receiving the fetch events, which is closed once the crawler finishes.
+func (sm *StatsAPI) subscribe() chan FetchEvent {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	events := make(chan FetchEvent, eventsBufferSize)
+	if sm.closed {
+		close(events)
+		return events
+	}
+
+	sm.subscribers[events] = struct{}{}
+	return events
+}
+
+// unsubscribe stops sending fetch events to the channel.
+func (sm *StatsAPI) unsubscribe(events chan FetchEvent) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	delete(sm.subscribers, events)
+}
+
+// getInspector returns the Inspector, replying with an error if there is none.
+func (sm *StatsAPI) getInspector(w http.ResponseWriter) (Inspector, bool) {
+	sm.mu.Lock()
+	inspector := sm.inspector
+	sm.mu.Unlock()
+
+	if inspector == nil {
+		httpjson.WriteError(w, http.StatusServiceUnavailable, fmt.Errorf("no crawl to inspect"))
+		return nil, false
+	}
+
+	return inspector, true
+}
@@ -0,0 +1,166 @@
+package wcrawler_test
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gustavooferreira/wcrawler"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStatsAPIStatsAndErrors(t *testing.T) {
+	sm := wcrawler.NewStatsAPI(4, 2)
+	sm.IncDecTotalRequestsCount(3)
+	sm.IncDecErrorsCount(2)
+	sm.AddErrorEntry("[timeout] error 1")
+	sm.AddErrorEntry("[http_4xx] error 2")
+
+	tests := map[string]struct {
+		target             string
+		expectedStatusCode int
+		expectedErrors     []string
+	}{
+		"all errors":     {target: "/errors", expectedStatusCode: http.StatusOK, expectedErrors: []string{"[timeout] error 1", "[http_4xx] error 2"}},
+		"last error":     {target: "/errors?n=1", expectedStatusCode: http.StatusOK, expectedErrors: []string{"[http_4xx] error 2"}},
+		"invalid number": {target: "/errors?n=-1", expectedStatusCode: http.StatusBadRequest},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			sm.ServeHTTP(w, httptest.NewRequest(http.MethodGet, test.target, nil))
+
+			assert.Equal(t, test.expectedStatusCode, w.Code)
+			if test.expectedStatusCode != http.StatusOK {
+				return
+			}
+
+			var errors []string
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &errors))
+			assert.Equal(t, test.expectedErrors, errors)
+		})
+	}
+
+	w := httptest.NewRecorder()
+	sm.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/stats", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var snapshot wcrawler.StatsSnapshot
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &snapshot))
+	assert.Equal(t, 3, snapshot.TotalRequestsCount)
+	assert.Equal(t, 2, snapshot.ErrorsCount)
+	assert.Equal(t, 4, snapshot.TotalWorkersCount)
+
+	w = httptest.NewRecorder()
+	sm.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/stats", nil))
+	assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+
+	// No crawler to inspect
+	w = httptest.NewRecorder()
+	sm.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/frontier", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+}
+
+func TestStatsAPIFrontierAndRecords(t *testing.T) {
+	sm := wcrawler.NewStatsAPI(1, 0)
+	connector := &slowConnector{fakeConnector: fakeConnector{pages: manyPages}, delay: 20 * time.Millisecond}
+	c, err := wcrawler.NewCrawler(connector, "http://example.com/", 0, &bytes.Buffer{}, sm, false, false, 1, 0)
+	require.NoError(t, err)
+	sm.SetInspector(c)
+
+	done := make(chan struct{})
+	go func() {
+		c.Run()
+		close(done)
+	}()
+
+	// Pause once the links in the first page are queued
+	for connector.requestsCount() < 2 {
+		time.Sleep(time.Millisecond)
+	}
+	c.Pause()
+
+	w := httptest.NewRecorder()
+	sm.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/frontier?n=2", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var frontier wcrawler.Frontier
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &frontier))
+	assert.Greater(t, frontier.Size, 2)
+	assert.Len(t, frontier.Sample, 2)
+
+	w = httptest.NewRecorder()
+	sm.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/records", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+
+	rm := wcrawler.NewRecordManager()
+	require.NoError(t, rm.LoadFromReader(w.Body))
+	assert.Equal(t, 8, rm.Count())
+	r, ok := rm.Get("http://example.com/")
+	require.True(t, ok)
+	assert.Equal(t, 200, r.StatusCode)
+
+	c.Resume()
+	<-done
+
+	// The records of the finished crawl are still available
+	w = httptest.NewRecorder()
+	sm.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/records", nil))
+	rm = wcrawler.NewRecordManager()
+	require.NoError(t, rm.LoadFromReader(w.Body))
+	assert.Equal(t, 9, rm.Count())
+
+	w = httptest.NewRecorder()
+	sm.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/frontier", nil))
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &frontier))
+	assert.Equal(t, 0, frontier.Size)
+	assert.Empty(t, frontier.Sample)
+}
+
+func TestStatsAPIEvents(t *testing.T) {
+	sm := wcrawler.NewStatsAPI(1, 0)
+	server := httptest.NewServer(sm)
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/events")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+	reader := bufio.NewReader(resp.Body)
+	readEvent := func() string {
+		var lines []string
+		for {
+			line, err := reader.ReadString('\n')
+			require.NoError(t, err)
+			if line == "\n" {
+				return strings.Join(lines, "")
+			}
+			lines = append(lines, line)
+		}
+	}
+
+	assert.Equal(t, ": connected\n", readEvent())
+
+	sm.AddFetchEvent(wcrawler.FetchEvent{URL: "http://example.com/", Host: "example.com", StatusCode: 200})
+	event := readEvent()
+	assert.True(t, strings.HasPrefix(event, "event: fetch\ndata: "))
+
+	var fetch wcrawler.FetchEvent
+	require.NoError(t, json.Unmarshal([]byte(strings.TrimPrefix(event, "event: fetch\ndata: ")), &fetch))
+	assert.Equal(t, "http://example.com/", fetch.URL)
+	assert.Equal(t, 200, fetch.StatusCode)
+
+	sm.SetAppState(wcrawler.AppState_Finished)
+	go sm.RunOutputFlusher()
+
+	event = readEvent()
+	assert.True(t, strings.HasPrefix(event, "event: finished\ndata: "))
+}
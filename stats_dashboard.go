@@ -0,0 +1,342 @@
+package wcrawler
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/gustavooferreira/wcrawler/internal/term"
+)
+
+// StatsDashboard keeps track of stats and draws a full-screen dashboard on a terminal:
+// global stats, a table of hosts and a log of the last requests made.
+// Keys read with HandleInput steer the crawl through a Controller.
+type StatsDashboard struct {
+	statsCounters
+
+	// keep a reference to where to draw the dashboard
+	writer io.Writer
+	// size returns the width and height of the terminal
+	size func() (width int, height int)
+
+	// the fields below are protected by the mutex in statsCounters
+	controller Controller
+	// host selected in the hosts table (to be skipped)
+	selectedHost string
+	// message shown after a key is pressed
+	message string
+	started time.Time
+}
+
+// NewStatsDashboard returns a new StatsDashboard.
+// size returns the size of the terminal, if nil the dashboard is drawn for a 80x24 terminal.
+func NewStatsDashboard(writer io.Writer, size func() (width int, height int), totalWorkersCount int, depth int) *StatsDashboard {
+	if size == nil {
+		size = func() (int, int) { return 80, 24 }
+	}
+
+	sm := StatsDashboard{writer: writer, size: size}
+	sm.init(totalWorkersCount, depth)
+	return &sm
+}
+
+// SetController sets the Controller used to steer the crawl when keys are pressed.
+func (sm *StatsDashboard) SetController(controller Controller) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	sm.controller = controller
+}
+
+// RunOutputFlusher draws the dashboard every 200 milliseconds until the crawler finishes.
+// The dashboard is drawn on the terminal's alternate screen, which is left once the crawler finishes.
+// Run this in a goroutine
+func (sm *StatsDashboard) RunOutputFlusher() {
+	sm.mu.Lock()
+	sm.started = time.Now()
+	sm.mu.Unlock()
+
+	// Switch to the alternate screen and hide the cursor
+	fmt.Fprint(sm.writer, "\x1b[?1049h\x1b[?25l")
+
+	for {
+		width, height := sm.size()
+		blocked := sm.blockedHosts()
+
+		sm.mu.Lock()
+		frame := sm.frame(width, height, blocked)
+		state := sm.state
+		sm.mu.Unlock()
+
+		fmt.Fprint(sm.writer, frame)
+
+		if state == AppState_Finished {
+			break
+		}
+
+		time.Sleep(time.Millisecond * 200)
+	}
+
+	// Show the cursor and go back to the main screen
+	fmt.Fprint(sm.writer, "\x1b[?25h\x1b[?1049l")
+}
+
+// HandleInput reads keys from the reader (a terminal in raw mode) until it returns an error.
+// quit is called when 'q' or Ctrl-C is pressed.
+func (sm *StatsDashboard) HandleInput(reader io.Reader, quit func()) {
+	term.ReadKeys(reader, func(key byte) bool {
+		if key == 'q' || key == term.KeyCtrlC {
+			quit()
+			return false
+		}
+
+		sm.handleKey(key)
+		return true
+	})
+}
+
+// handleKey applies the action bound to a key.
+func (sm *StatsDashboard) handleKey(key byte) {
+	sm.mu.Lock()
+	controller := sm.controller
+	sm.mu.Unlock()
+
+	if controller == nil {
+		return
+	}
+
+	var message string
+
+	// Controller methods can call back into the stats (e.g. to change the state), so the lock isn't held
+	switch key {
+	case 'p', ' ':
+		if controller.Paused() {
+			controller.Resume()
+			message = "resumed"
+		} else {
+			controller.Pause()
+			message = "paused, requests in flight will complete"
+		}
+	case '+', '=':
+		n := controller.Workers() + 1
+		if err := controller.SetWorkers(n); err == nil {
+			message = fmt.Sprintf("workers set to %d", n)
+		}
+	case '-', '_':
+		n := controller.Workers() - 1
+		if err := controller.SetWorkers(n); err == nil {
+			message = fmt.Sprintf("workers set to %d", n)
+		} else {
+			message = err.Error()
+		}
+	case 'j', 'k':
+		sm.mu.Lock()
+		sm.moveSelection(key)
+		sm.mu.Unlock()
+		return
+	case 's':
+		sm.mu.Lock()
+		host := sm.selectedHost
+		sm.mu.Unlock()
+
+		if host == "" {
+			message = "no host selected"
+		} else {
+			controller.BlockHost(host)
+			message = fmt.Sprintf("skipping host %s", host)
+		}
+	default:
+		return
+	}
+
+	sm.mu.Lock()
+	sm.message = message
+	sm.mu.Unlock()
+}
+
+// moveSelection selects the previous ('k') or next ('j') host in the hosts table.
+// Lock must be held by the caller.
+func (sm *StatsDashboard) moveSelection(key byte) {
+	hosts := sm.busiestHosts(len(sm.hosts))
+	if len(hosts) == 0 {
+		return
+	}
+
+	index := -1
+	for i, host := range hosts {
+		if host == sm.selectedHost {
+			index = i
+		}
+	}
+
+	if key == 'j' && index < len(hosts)-1 {
+		index++
+	} else if key == 'k' && index > 0 {
+		index--
+	}
+
+	if index < 0 {
+		index = 0
+	}
+
+	sm.selectedHost = hosts[index]
+}
+
+// blockedHosts returns the hosts blocked by the controller.
+// The lock mustn't be held, as the controller can call back into the stats.
+func (sm *StatsDashboard) blockedHosts() map[string]bool {
+	sm.mu.Lock()
+	controller := sm.controller
+	sm.mu.Unlock()
+
+	blocked := map[string]bool{}
+	if controller != nil {
+		for _, host := range controller.BlockedHosts() {
+			blocked[host] = true
+		}
+	}
+
+	return blocked
+}
+
+// frame returns the dashboard to draw, fitting the given size. Lock must be held by the caller.
+func (sm *StatsDashboard) frame(width int, height int, blocked map[string]bool) string {
+	// Don't bother fitting in tiny terminals
+	if width < minDashboardWidth {
+		width = minDashboardWidth
+	}
+	if height < minDashboardHeight {
+		height = minDashboardHeight
+	}
+
+	var lines []string
+	add := func(format string, a ...interface{}) {
+		lines = append(lines, fmt.Sprintf(format, a...))
+	}
+
+	now := sm.clock(time.Now())
+	throughput := sm.throughput(now)
+
+	errorsPerc := 0.0
+	if sm.totalRequestsCount != 0 {
+		errorsPerc = 100 * float64(sm.errorCounts) / float64(sm.totalRequestsCount)
+	}
+
+	add("wcrawler   State: %-8s   Elapsed: %s   Workers: %d/%d busy   Depth: %d/%d",
+		sm.state, now.Sub(sm.started).Round(time.Second), sm.workersRunning, sm.totalWorkersCount,
+		sm.depth, sm.maxDepthLevel)
+	add("Links: %d cached, %d in queue   Requests: %d   Errors: %d (%.2f%%)",
+		sm.linksCount, sm.linksInQueue, sm.totalRequestsCount, sm.errorCounts, errorsPerc)
+	add("Rate: %.1f req/s (smoothed %.1f)   Download: %s/s   ETA (queued links): %s",
+		throughput.RequestsPerSecond, throughput.SmoothedRequestsPerSecond,
+		formatBytes(throughput.BytesPerSecond), formatETA(throughput.ETA))
+
+	series := sm.requestsWindow.Series(now)
+	if len(series) > width-12 {
+		series = series[len(series)-(width-12):]
+	}
+	add("Throughput: %s", sparkline(series))
+
+	if len(sm.errorCategories) != 0 {
+		var b strings.Builder
+		for _, category := range sm.topErrorCategories(maxErrorCategoriesDisplayed) {
+			fmt.Fprintf(&b, "  %s: %d", category, sm.errorCategories[category])
+		}
+		add("Top errors:%s", b.String())
+	}
+
+	// Split the space left between the hosts table and the log of requests
+	footer := []string{
+		sectionLine("", width),
+		"[p] pause/resume  [+/-] workers  [↑/↓] select host  [s] skip host  [q] quit",
+	}
+	if sm.message != "" {
+		footer = append(footer, "> "+sm.message)
+	}
+
+	space := height - len(lines) - len(footer) - 4
+	hostRows := space / 2
+	if hostRows < 1 {
+		hostRows = 1
+	}
+
+	add(sectionLine("Hosts", width))
+	add("  %-30s %9s %7s %7s %8s", "HOST", "IN FLIGHT", "DONE", "ERRORS", "AVG (s)")
+	for _, host := range sm.busiestHosts(hostRows) {
+		hl := sm.hosts[host]
+
+		cursor := " "
+		if host == sm.selectedHost {
+			cursor = ">"
+		}
+
+		name := host
+		if blocked[host] {
+			name += " [skipped]"
+		}
+
+		errors := 0
+		for _, count := range hl.errors {
+			errors += count
+		}
+
+		add("%s %-30s %9d %7d %7d %8.3f", cursor, truncate(name, 30), hl.workersRunning, hl.requests, errors,
+			hl.totalTime.Mean().Seconds())
+	}
+
+	add(sectionLine("Last requests", width))
+	logRows := height - len(lines) - len(footer)
+	fetches := sm.fetchesList.ReadAll()
+	if logRows < 0 {
+		logRows = 0
+	}
+	if len(fetches) > logRows {
+		fetches = fetches[len(fetches)-logRows:]
+	}
+	for _, fetch := range fetches {
+		add("%s", fetch)
+	}
+
+	// Keep the footer at the bottom
+	for len(lines)+len(footer) < height {
+		add("")
+	}
+	lines = append(lines, footer...)
+
+	if len(lines) > height {
+		lines = lines[:height]
+	}
+
+	var b strings.Builder
+	// Move the cursor to the top left corner and draw every line, clearing what was left from the previous frame
+	b.WriteString("\x1b[H")
+	for i, line := range lines {
+		b.WriteString(truncate(line, width))
+		b.WriteString("\x1b[K")
+		if i != len(lines)-1 {
+			b.WriteString("\r\n")
+		}
+	}
+	b.WriteString("\x1b[J")
+
+	return b.String()
+}
+
+const (
+	minDashboardWidth  = 40
+	minDashboardHeight = 12
+)
+
+// sectionLine returns a line separating sections of the dashboard, with an optional title.
+func sectionLine(title string, width int) string {
+	line := "──"
+	if title != "" {
+		line += " " + title + " "
+	}
+
+	if n := width - len([]rune(line)); n > 0 {
+		line += strings.Repeat("─", n)
+	}
+
+	return line
+}
@@ -0,0 +1,86 @@
+package wcrawler_test
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gustavooferreira/wcrawler"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeController records the calls made to it.
+type fakeController struct {
+	paused       bool
+	workers      int
+	blockedHosts []string
+	maxDepth     int
+}
+
+func (fc *fakeController) Pause()       { fc.paused = true }
+func (fc *fakeController) Resume()      { fc.paused = false }
+func (fc *fakeController) Paused() bool { return fc.paused }
+func (fc *fakeController) Workers() int { return fc.workers }
+func (fc *fakeController) BlockHost(host string) {
+	fc.blockedHosts = append(fc.blockedHosts, host)
+}
+func (fc *fakeController) BlockedHosts() []string { return fc.blockedHosts }
+func (fc *fakeController) MaxDepth() int          { return fc.maxDepth }
+func (fc *fakeController) SetMaxDepth(depth int) error {
+	fc.maxDepth = depth
+	return nil
+}
+func (fc *fakeController) SetWorkers(n int) error {
+	if n <= 0 {
+		return fmt.Errorf("the number of workers needs to be greater than 0")
+	}
+	fc.workers = n
+	return nil
+}
+
+func TestStatsDashboardKeys(t *testing.T) {
+	sm := wcrawler.NewStatsDashboard(&bytes.Buffer{}, nil, 2, 3)
+	controller := &fakeController{workers: 2}
+	sm.SetController(controller)
+
+	sm.AddResponseSample("busy.com", 200, wcrawler.ResponseInfo{}, nil)
+	sm.AddResponseSample("busy.com", 200, wcrawler.ResponseInfo{}, nil)
+	sm.AddResponseSample("slow.com", 200, wcrawler.ResponseInfo{}, nil)
+
+	quit := false
+	// pause, 2 more workers, 1 less, select the second host (arrow down twice) and skip it, then quit
+	input := "p++-\x1b[B\x1b[Bsqp"
+	sm.HandleInput(strings.NewReader(input), func() { quit = true })
+
+	assert.True(t, quit)
+	assert.True(t, controller.paused, "keys after quitting must be ignored")
+	assert.Equal(t, 3, controller.workers)
+	assert.Equal(t, []string{"slow.com"}, controller.blockedHosts)
+}
+
+func TestStatsDashboardFrame(t *testing.T) {
+	var buf bytes.Buffer
+	sm := wcrawler.NewStatsDashboard(&buf, func() (int, int) { return 100, 30 }, 2, 3)
+	sm.SetController(&fakeController{workers: 2, blockedHosts: []string{"other.com"}})
+
+	sm.AddResponseSample("example.com", 200, wcrawler.ResponseInfo{}, nil)
+	sm.AddResponseSample("other.com", 404, wcrawler.ResponseInfo{}, nil)
+	sm.AddFetchEvent(wcrawler.FetchEvent{URL: "http://other.com/missing", Host: "other.com", StatusCode: 404,
+		ErrorCategory: wcrawler.ErrorCategory_HTTP4xx, Duration: 100 * time.Millisecond})
+	sm.SetAppState(wcrawler.AppState_Finished)
+
+	sm.RunOutputFlusher()
+
+	output := buf.String()
+	assert.Contains(t, output, "State: Finished")
+	assert.Contains(t, output, "example.com")
+	assert.Contains(t, output, "other.com [skipped]")
+	assert.Contains(t, output, "http://other.com/missing")
+	assert.Contains(t, output, "Top errors:  http_4xx: 1")
+
+	// The terminal is left as it was found
+	assert.True(t, strings.HasPrefix(output, "\x1b[?1049h"))
+	assert.True(t, strings.HasSuffix(output, "\x1b[?1049l"))
+}
@@ -0,0 +1,131 @@
+package wcrawler
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// ProgressEvent represents the progress of the crawler at a given point in time.
+// It's written as a JSON line by StatsProgressWriter when using ProgressFormat_JSON.
+type ProgressEvent struct {
+	// Type is either 'progress' or 'finished' (the last event)
+	Type string    `json:"type"`
+	Time time.Time `json:"time"`
+	// Elapsed represents the seconds since the crawler started
+	Elapsed            float64 `json:"elapsed"`
+	State              string  `json:"state"`
+	LinksCount         int     `json:"linksCount"`
+	LinksInQueue       int     `json:"linksInQueue"`
+	TotalRequestsCount int     `json:"totalRequestsCount"`
+	ErrorsCount        int     `json:"errorsCount"`
+	WorkersRunning     int     `json:"workersRunning"`
+	TotalWorkersCount  int     `json:"totalWorkersCount"`
+	Depth              int     `json:"depth"`
+	MaxDepthLevel      int     `json:"maxDepthLevel"`
+	// Rates are computed over a sliding window of the last minute
+	RequestsPerSecond float64 `json:"requestsPerSecond"`
+	BytesPerSecond    float64 `json:"bytesPerSecond"`
+	ErrorsPerSecond   float64 `json:"errorsPerSecond"`
+	// ETA represents the estimated seconds left to process the links queued so far (see Throughput.ETA),
+	// only available when the crawl is bounded by depth
+	ETA *float64 `json:"eta"`
+	// ErrorCategories counts the failed requests per category
+	ErrorCategories map[string]int `json:"errorCategories"`
+}
+
+// StatsProgressWriter keeps track of stats and periodically writes the progress of the crawler
+// as single lines, which suits logs and piped output better than a redrawn block.
+type StatsProgressWriter struct {
+	statsCounters
+
+	// keep a reference to where to write progress
+	writer   io.Writer
+	format   ProgressFormat
+	interval time.Duration
+}
+
+// NewStatsProgressWriter returns a new StatsProgressWriter writing a line every interval.
+func NewStatsProgressWriter(writer io.Writer, format ProgressFormat, interval time.Duration, totalWorkersCount int, depth int) *StatsProgressWriter {
+	sm := StatsProgressWriter{
+		writer:   writer,
+		format:   format,
+		interval: interval,
+	}
+	sm.init(totalWorkersCount, depth)
+	return &sm
+}
+
+// RunOutputFlusher writes a progress line every interval, and a last one when the crawler finishes.
+// Run this in a goroutine
+func (sm *StatsProgressWriter) RunOutputFlusher() {
+	start := time.Now()
+	lastWrite := start
+
+	for {
+		// Check the state more often than we write, so we don't linger once the crawler finishes
+		time.Sleep(time.Millisecond * 200)
+
+		finished := sm.finished()
+		now := time.Now()
+		if !finished && now.Sub(lastWrite) < sm.interval {
+			continue
+		}
+
+		event := sm.progressEvent(now, start)
+		if finished {
+			event.Type = "finished"
+		}
+
+		sm.writeEvent(event)
+		lastWrite = now
+
+		if finished {
+			break
+		}
+	}
+}
+
+// progressEvent returns the current progress.
+func (sm *StatsProgressWriter) progressEvent(now time.Time, start time.Time) ProgressEvent {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	throughput := sm.throughput(now)
+
+	event := ProgressEvent{
+		Type:               "progress",
+		Time:               now,
+		Elapsed:            now.Sub(start).Seconds(),
+		State:              sm.state.String(),
+		LinksCount:         sm.linksCount,
+		LinksInQueue:       sm.linksInQueue,
+		TotalRequestsCount: sm.totalRequestsCount,
+		ErrorsCount:        sm.errorCounts,
+		WorkersRunning:     sm.workersRunning,
+		TotalWorkersCount:  sm.totalWorkersCount,
+		Depth:              sm.depth,
+		MaxDepthLevel:      sm.maxDepthLevel,
+		RequestsPerSecond:  throughput.RequestsPerSecond,
+		BytesPerSecond:     throughput.BytesPerSecond,
+		ErrorsPerSecond:    throughput.ErrorsPerSecond,
+		ETA:                throughput.ETA,
+		ErrorCategories:    categoryCounts(sm.errorCategories),
+	}
+
+	return event
+}
+
+func (sm *StatsProgressWriter) writeEvent(event ProgressEvent) {
+	if sm.format == ProgressFormat_JSON {
+		json.NewEncoder(sm.writer).Encode(event)
+		return
+	}
+
+	fmt.Fprintf(sm.writer, "[%8.1fs] state=%s links=%d queue=%d requests=%d errors=%d workers=%d/%d depth=%d/%d rps=%.1f download=%s/s queue_eta=%s\n",
+		event.Elapsed, event.State, event.LinksCount, event.LinksInQueue, event.TotalRequestsCount, event.ErrorsCount,
+		event.WorkersRunning, event.TotalWorkersCount, event.Depth, event.MaxDepthLevel, event.RequestsPerSecond,
+		strings.ReplaceAll(formatBytes(event.BytesPerSecond), " ", ""), formatETA(event.ETA))
+}
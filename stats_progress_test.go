@@ -0,0 +1,75 @@
+package wcrawler_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gustavooferreira/wcrawler"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStatsProgressWriter(t *testing.T) {
+	tests := map[string]struct {
+		format wcrawler.ProgressFormat
+		check  func(t *testing.T, lines []string)
+	}{
+		"plain": {
+			format: wcrawler.ProgressFormat_Plain,
+			check: func(t *testing.T, lines []string) {
+				last := lines[len(lines)-1]
+				assert.Contains(t, last, "state=Finished links=3 queue=0 requests=3 errors=1 workers=0/2 depth=1/3")
+			},
+		},
+		"json": {
+			format: wcrawler.ProgressFormat_JSON,
+			check: func(t *testing.T, lines []string) {
+				for _, line := range lines {
+					var event wcrawler.ProgressEvent
+					require.NoError(t, json.Unmarshal([]byte(line), &event))
+				}
+
+				var last wcrawler.ProgressEvent
+				require.NoError(t, json.Unmarshal([]byte(lines[len(lines)-1]), &last))
+				assert.Equal(t, "finished", last.Type)
+				assert.Equal(t, "Finished", last.State)
+				assert.Equal(t, 3, last.TotalRequestsCount)
+				assert.Equal(t, 1, last.ErrorsCount)
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var buf bytes.Buffer
+			sm := wcrawler.NewStatsProgressWriter(&buf, test.format, 100*time.Millisecond, 2, 3)
+
+			done := make(chan struct{})
+			go func() {
+				sm.RunOutputFlusher()
+				close(done)
+			}()
+
+			sm.SetAppState(wcrawler.AppState_Running)
+			sm.SetLinksCount(3)
+			sm.IncDecTotalRequestsCount(3)
+			sm.IncDecErrorsCount(1)
+			sm.SetDepth(1)
+			time.Sleep(500 * time.Millisecond)
+			sm.SetAppState(wcrawler.AppState_Finished)
+
+			select {
+			case <-done:
+			case <-time.After(5 * time.Second):
+				require.FailNow(t, "output flusher didn't return after the crawler finished")
+			}
+
+			lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+			require.GreaterOrEqual(t, len(lines), 2)
+			test.check(t, lines)
+		})
+	}
+}
@@ -0,0 +1,137 @@
+package wcrawler
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+// latencyBuckets represents the upper bounds (in seconds) of the buckets of the latency histogram exposed.
+var latencyBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// StatsPrometheus keeps track of stats and exposes them in the Prometheus text format.
+// It implements http.Handler, so it can be served on a metrics endpoint.
+type StatsPrometheus struct {
+	statsCounters
+}
+
+// NewStatsPrometheus returns a new StatsPrometheus.
+func NewStatsPrometheus(totalWorkersCount int, depth int) *StatsPrometheus {
+	sm := StatsPrometheus{}
+	sm.init(totalWorkersCount, depth)
+	return &sm
+}
+
+// RunOutputFlusher doesn't do anything as metrics are pulled through the HTTP handler.
+func (sm *StatsPrometheus) RunOutputFlusher() {}
+
+// ServeHTTP writes the metrics in the Prometheus text exposition format.
+func (sm *StatsPrometheus) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	sm.WriteMetrics(w)
+}
+
+// WriteMetrics writes the metrics in the Prometheus text exposition format.
+func (sm *StatsPrometheus) WriteMetrics(w io.Writer) error {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	var b strings.Builder
+
+	writeHeader(&b, "wcrawler_state", "Current state of the crawler.", "gauge")
+	for _, state := range []AppState{AppState_IDLE, AppState_Running, AppState_Paused, AppState_Finished} {
+		value := 0
+		if sm.state == state {
+			value = 1
+		}
+		fmt.Fprintf(&b, "wcrawler_state{state=\"%s\"} %d\n", state, value)
+	}
+
+	writeGauge(&b, "wcrawler_links_in_queue", "Number of links waiting to be requested.", float64(sm.linksInQueue))
+	writeGauge(&b, "wcrawler_links_discovered", "Number of unique links discovered.", float64(sm.linksCount))
+	writeGauge(&b, "wcrawler_workers_busy", "Number of workers making a request.", float64(sm.workersRunning))
+	writeGauge(&b, "wcrawler_workers", "Total number of workers.", float64(sm.totalWorkersCount))
+	writeGauge(&b, "wcrawler_depth", "Depth level of the last page processed.", float64(sm.depth))
+	writeGauge(&b, "wcrawler_max_depth", "Max depth level (0 means no limit).", float64(sm.maxDepthLevel))
+
+	writeHeader(&b, "wcrawler_requests_total", "Total number of requests made, by status class.", "counter")
+	for _, class := range sortedKeys(sm.statusClasses) {
+		fmt.Fprintf(&b, "wcrawler_requests_total{class=\"%s\"} %d\n", class, sm.statusClasses[class])
+	}
+
+	writeHeader(&b, "wcrawler_errors_total", "Total number of failed requests, by category.", "counter")
+	errorCategories := categoryCounts(sm.errorCategories)
+	for _, category := range sortedKeys(errorCategories) {
+		fmt.Fprintf(&b, "wcrawler_errors_total{category=\"%s\"} %d\n", category, errorCategories[category])
+	}
+
+	writeHeader(&b, "wcrawler_downloaded_bytes_total", "Total number of bytes downloaded.", "counter")
+	fmt.Fprintf(&b, "wcrawler_downloaded_bytes_total %d\n", sm.bytesDownloaded)
+
+	writeHeader(&b, "wcrawler_request_latency_seconds", "Time to first byte of the requests made.", "histogram")
+	for _, bound := range latencyBuckets {
+		count := sm.ttfb.CountAtMost(time.Duration(bound * float64(time.Second)))
+		fmt.Fprintf(&b, "wcrawler_request_latency_seconds_bucket{le=\"%g\"} %d\n", bound, count)
+	}
+	fmt.Fprintf(&b, "wcrawler_request_latency_seconds_bucket{le=\"+Inf\"} %d\n", sm.ttfb.Count())
+	fmt.Fprintf(&b, "wcrawler_request_latency_seconds_sum %g\n", sm.ttfb.Sum().Seconds())
+	fmt.Fprintf(&b, "wcrawler_request_latency_seconds_count %d\n", sm.ttfb.Count())
+
+	hosts := make([]string, 0, len(sm.hosts))
+	for host := range sm.hosts {
+		hosts = append(hosts, host)
+	}
+	sort.Strings(hosts)
+
+	writeHeader(&b, "wcrawler_host_requests_total", "Total number of requests made, by host.", "counter")
+	for _, host := range hosts {
+		fmt.Fprintf(&b, "wcrawler_host_requests_total{host=\"%s\"} %d\n", escapeLabelValue(host), sm.hosts[host].requests)
+	}
+
+	writeHeader(&b, "wcrawler_host_errors_total", "Total number of failed requests, by host.", "counter")
+	for _, host := range hosts {
+		errors := 0
+		for _, count := range sm.hosts[host].errors {
+			errors += count
+		}
+		if errors != 0 {
+			fmt.Fprintf(&b, "wcrawler_host_errors_total{host=\"%s\"} %d\n", escapeLabelValue(host), errors)
+		}
+	}
+
+	writeHeader(&b, "wcrawler_host_downloaded_bytes_total", "Total number of bytes downloaded, by host.", "counter")
+	for _, host := range hosts {
+		fmt.Fprintf(&b, "wcrawler_host_downloaded_bytes_total{host=\"%s\"} %d\n", escapeLabelValue(host), sm.hosts[host].bytes)
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func writeHeader(b *strings.Builder, name string, help string, metricType string) {
+	fmt.Fprintf(b, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, metricType)
+}
+
+func writeGauge(b *strings.Builder, name string, help string, value float64) {
+	writeHeader(b, name, help, "gauge")
+	fmt.Fprintf(b, "%s %g\n", name, value)
+}
+
+// escapeLabelValue escapes a label value as required by the Prometheus text format.
+func escapeLabelValue(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(value)
+}
+
+// sortedKeys returns the keys of the map sorted alphabetically.
+func sortedKeys(m map[string]int) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
@@ -0,0 +1,70 @@
+package wcrawler_test
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gustavooferreira/wcrawler"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStatsPrometheus(t *testing.T) {
+	sm := wcrawler.NewStatsPrometheus(10, 5)
+
+	sm.SetAppState(wcrawler.AppState_Running)
+	sm.SetLinksInQueue(3)
+	sm.IncDecWorkersRunning(2)
+	sm.AddLatencySample(20 * time.Millisecond)
+	sm.AddLatencySample(3 * time.Second)
+	sm.AddResponseSample("example.com", 200, wcrawler.ResponseInfo{BytesRead: 100}, nil)
+	sm.AddResponseSample("example.com", 404, wcrawler.ResponseInfo{BytesRead: 20}, nil)
+	sm.AddResponseSample("other.com", 0, wcrawler.ResponseInfo{}, fmt.Errorf("connection refused"))
+
+	ts := httptest.NewServer(sm)
+	defer ts.Close()
+
+	resp, err := http.Get(ts.URL)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	require.NoError(t, err)
+
+	expectedLines := []string{
+		`wcrawler_state{state="Running"} 1`,
+		`wcrawler_links_in_queue 3`,
+		`wcrawler_workers_busy 2`,
+		`wcrawler_workers 10`,
+		`wcrawler_requests_total{class="2xx"} 1`,
+		`wcrawler_requests_total{class="4xx"} 1`,
+		`wcrawler_requests_total{class="error"} 1`,
+		`wcrawler_errors_total{category="http_4xx"} 1`,
+		`wcrawler_errors_total{category="connection"} 1`,
+		`wcrawler_downloaded_bytes_total 120`,
+		`wcrawler_request_latency_seconds_bucket{le="0.025"} 1`,
+		`wcrawler_request_latency_seconds_bucket{le="2.5"} 1`,
+		`wcrawler_request_latency_seconds_bucket{le="5"} 2`,
+		`wcrawler_request_latency_seconds_bucket{le="+Inf"} 2`,
+		`wcrawler_request_latency_seconds_count 2`,
+		`wcrawler_host_requests_total{host="example.com"} 2`,
+		`wcrawler_host_errors_total{host="other.com"} 1`,
+		`wcrawler_host_downloaded_bytes_total{host="example.com"} 120`,
+	}
+
+	for _, line := range expectedLines {
+		assert.Contains(t, string(body), line+"\n")
+	}
+
+	// The metrics come from the same counters as the other sinks
+	snapshot := sm.Snapshot()
+	assert.Contains(t, string(body), fmt.Sprintf("wcrawler_request_latency_seconds_count %d\n", snapshot.TTFB.Count))
+	assert.Contains(t, string(body), fmt.Sprintf("wcrawler_host_requests_total{host=\"other.com\"} %d\n",
+		snapshot.Hosts["other.com"].Requests))
+	assert.Contains(t, string(body), fmt.Sprintf("wcrawler_errors_total{category=\"http_4xx\"} %d\n",
+		snapshot.ErrorCategories["http_4xx"]))
+}
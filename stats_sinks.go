@@ -0,0 +1,209 @@
+package wcrawler
+
+import (
+	"encoding/json"
+	"io"
+	"sync"
+	"time"
+)
+
+// NoopStatsManager is a StatsManager that doesn't keep track of anything.
+// It's used by the Crawler when no StatsManager is provided.
+type NoopStatsManager struct{}
+
+func (NoopStatsManager) SetAppState(state AppState)                                            {}
+func (NoopStatsManager) SetLinksInQueue(value int)                                             {}
+func (NoopStatsManager) IncDecLinksInQueue(value int)                                          {}
+func (NoopStatsManager) SetLinksCount(value int)                                               {}
+func (NoopStatsManager) IncDecLinksCount(value int)                                            {}
+func (NoopStatsManager) SetErrorsCount(value int)                                              {}
+func (NoopStatsManager) IncDecErrorsCount(value int)                                           {}
+func (NoopStatsManager) SetWorkersRunning(value int)                                           {}
+func (NoopStatsManager) IncDecWorkersRunning(value int)                                        {}
+func (NoopStatsManager) SetTotalWorkersCount(value int)                                        {}
+func (NoopStatsManager) SetMaxDepthLevel(value int)                                            {}
+func (NoopStatsManager) IncDecHostWorkersRunning(host string, value int)                       {}
+func (NoopStatsManager) SetTotalRequestsCount(value int)                                       {}
+func (NoopStatsManager) IncDecTotalRequestsCount(value int)                                    {}
+func (NoopStatsManager) SetDepth(value int)                                                    {}
+func (NoopStatsManager) IncDecDepth(value int)                                                 {}
+func (NoopStatsManager) AddLatencySample(value time.Duration)                                  {}
+func (NoopStatsManager) AddErrorEntry(value string)                                            {}
+func (NoopStatsManager) AddFetchEvent(event FetchEvent)                                        {}
+func (NoopStatsManager) AddResponseSample(host string, code int, info ResponseInfo, err error) {}
+func (NoopStatsManager) RunOutputFlusher()                                                     {}
+
+// MultiStatsManager forwards every call to several StatsManagers,
+// so multiple sinks (e.g. terminal output and metrics) can be active at the same time.
+type MultiStatsManager struct {
+	managers []StatsManager
+}
+
+// NewMultiStatsManager returns a new MultiStatsManager.
+func NewMultiStatsManager(managers ...StatsManager) *MultiStatsManager {
+	return &MultiStatsManager{managers: managers}
+}
+
+func (msm *MultiStatsManager) SetAppState(state AppState) {
+	for _, sm := range msm.managers {
+		sm.SetAppState(state)
+	}
+}
+
+func (msm *MultiStatsManager) SetLinksInQueue(value int) {
+	for _, sm := range msm.managers {
+		sm.SetLinksInQueue(value)
+	}
+}
+
+func (msm *MultiStatsManager) IncDecLinksInQueue(value int) {
+	for _, sm := range msm.managers {
+		sm.IncDecLinksInQueue(value)
+	}
+}
+
+func (msm *MultiStatsManager) SetLinksCount(value int) {
+	for _, sm := range msm.managers {
+		sm.SetLinksCount(value)
+	}
+}
+
+func (msm *MultiStatsManager) IncDecLinksCount(value int) {
+	for _, sm := range msm.managers {
+		sm.IncDecLinksCount(value)
+	}
+}
+
+func (msm *MultiStatsManager) SetErrorsCount(value int) {
+	for _, sm := range msm.managers {
+		sm.SetErrorsCount(value)
+	}
+}
+
+func (msm *MultiStatsManager) IncDecErrorsCount(value int) {
+	for _, sm := range msm.managers {
+		sm.IncDecErrorsCount(value)
+	}
+}
+
+func (msm *MultiStatsManager) SetWorkersRunning(value int) {
+	for _, sm := range msm.managers {
+		sm.SetWorkersRunning(value)
+	}
+}
+
+func (msm *MultiStatsManager) IncDecWorkersRunning(value int) {
+	for _, sm := range msm.managers {
+		sm.IncDecWorkersRunning(value)
+	}
+}
+
+func (msm *MultiStatsManager) SetTotalWorkersCount(value int) {
+	for _, sm := range msm.managers {
+		sm.SetTotalWorkersCount(value)
+	}
+}
+
+func (msm *MultiStatsManager) SetMaxDepthLevel(value int) {
+	for _, sm := range msm.managers {
+		sm.SetMaxDepthLevel(value)
+	}
+}
+
+func (msm *MultiStatsManager) IncDecHostWorkersRunning(host string, value int) {
+	for _, sm := range msm.managers {
+		sm.IncDecHostWorkersRunning(host, value)
+	}
+}
+
+func (msm *MultiStatsManager) SetTotalRequestsCount(value int) {
+	for _, sm := range msm.managers {
+		sm.SetTotalRequestsCount(value)
+	}
+}
+
+func (msm *MultiStatsManager) IncDecTotalRequestsCount(value int) {
+	for _, sm := range msm.managers {
+		sm.IncDecTotalRequestsCount(value)
+	}
+}
+
+func (msm *MultiStatsManager) SetDepth(value int) {
+	for _, sm := range msm.managers {
+		sm.SetDepth(value)
+	}
+}
+
+func (msm *MultiStatsManager) IncDecDepth(value int) {
+	for _, sm := range msm.managers {
+		sm.IncDecDepth(value)
+	}
+}
+
+func (msm *MultiStatsManager) AddLatencySample(value time.Duration) {
+	for _, sm := range msm.managers {
+		sm.AddLatencySample(value)
+	}
+}
+
+func (msm *MultiStatsManager) AddErrorEntry(value string) {
+	for _, sm := range msm.managers {
+		sm.AddErrorEntry(value)
+	}
+}
+
+func (msm *MultiStatsManager) AddFetchEvent(event FetchEvent) {
+	for _, sm := range msm.managers {
+		sm.AddFetchEvent(event)
+	}
+}
+
+func (msm *MultiStatsManager) AddResponseSample(host string, statusCode int, info ResponseInfo, err error) {
+	for _, sm := range msm.managers {
+		sm.AddResponseSample(host, statusCode, info, err)
+	}
+}
+
+// RunOutputFlusher runs the output flusher of every StatsManager concurrently
+// and only returns when all of them have returned.
+func (msm *MultiStatsManager) RunOutputFlusher() {
+	var wg sync.WaitGroup
+
+	for _, sm := range msm.managers {
+		wg.Add(1)
+		go func(sm StatsManager) {
+			defer wg.Done()
+			sm.RunOutputFlusher()
+		}(sm)
+	}
+
+	wg.Wait()
+}
+
+// StatsJSONWriter keeps track of stats and writes them in JSON format to a writer
+// once the crawler finishes.
+type StatsJSONWriter struct {
+	statsCounters
+
+	// keep a reference to where to write stats
+	writer io.Writer
+}
+
+// NewStatsJSONWriter returns a new StatsJSONWriter.
+func NewStatsJSONWriter(writer io.Writer, totalWorkersCount int, depth int) *StatsJSONWriter {
+	sm := StatsJSONWriter{writer: writer}
+	sm.init(totalWorkersCount, depth)
+	return &sm
+}
+
+// RunOutputFlusher waits for the crawler to finish and writes the final stats to the writer.
+// Run this in a goroutine
+func (sm *StatsJSONWriter) RunOutputFlusher() {
+	for !sm.finished() {
+		time.Sleep(time.Millisecond * 200)
+	}
+
+	encoder := json.NewEncoder(sm.writer)
+	encoder.SetIndent("", "    ")
+	encoder.Encode(sm.Snapshot())
+}
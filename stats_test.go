@@ -2,10 +2,13 @@ package wcrawler_test
 
 import (
 	"bytes"
+	"fmt"
 	"testing"
+	"time"
 
 	"github.com/gustavooferreira/wcrawler"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestNewStatsCLIOutWriterInstanciation(t *testing.T) {
@@ -17,3 +20,120 @@ func TestNewStatsCLIOutWriterInstanciation(t *testing.T) {
 
 	assert.Contains(t, buf.String(), "Crawler State:    Finished")
 }
+
+func TestStatsLatencySummary(t *testing.T) {
+	sm := wcrawler.NewStatsCLIOutWriter(&bytes.Buffer{}, false, 10, 5)
+
+	for i := 1; i <= 100; i++ {
+		latency := time.Duration(i) * time.Millisecond
+		sm.AddLatencySample(latency)
+
+		host := "example.com"
+		if i%4 == 0 {
+			host = "other.com"
+		}
+		sm.AddResponseSample(host, 200, wcrawler.ResponseInfo{Timings: wcrawler.Timings{TTFB: latency, Total: 2 * latency}}, nil)
+	}
+
+	snapshot := sm.Snapshot()
+
+	assert.Equal(t, uint64(100), snapshot.TTFB.Count)
+	assert.InDelta(t, 0.001, snapshot.TTFB.Min, 1e-9)
+	assert.InDelta(t, 0.1, snapshot.TTFB.Max, 1e-9)
+	assert.InEpsilon(t, 0.05, snapshot.TTFB.P50, 0.01)
+	assert.InEpsilon(t, 0.09, snapshot.TTFB.P90, 0.01)
+	assert.InEpsilon(t, 0.1, snapshot.TotalTime.P50, 0.01)
+
+	require.Len(t, snapshot.Hosts, 2)
+	assert.Equal(t, 75, snapshot.Hosts["example.com"].Requests)
+	assert.Equal(t, 25, snapshot.Hosts["other.com"].Requests)
+	assert.InDelta(t, 0.2, snapshot.Hosts["other.com"].TotalTime.Max, 1e-9)
+}
+
+func TestStatsThroughput(t *testing.T) {
+	tests := map[string]struct {
+		depth       int
+		expectedETA bool
+	}{
+		"bounded by depth": {depth: 5, expectedETA: true},
+		"no depth limit":   {depth: 0, expectedETA: false},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			sm := wcrawler.NewStatsCLIOutWriter(&bytes.Buffer{}, false, 10, test.depth)
+			sm.SetAppState(wcrawler.AppState_Running)
+			sm.SetLinksInQueue(50)
+
+			for i := 0; i < 10; i++ {
+				sm.AddResponseSample("example.com", 200, wcrawler.ResponseInfo{BytesRead: 100}, nil)
+			}
+			sm.AddResponseSample("example.com", 500, wcrawler.ResponseInfo{}, nil)
+
+			throughput := sm.Snapshot().Throughput
+
+			assert.Greater(t, throughput.RequestsPerSecond, 0.0)
+			assert.InEpsilon(t, 1000, throughput.BytesPerSecond/throughput.ErrorsPerSecond, 0.01)
+			assert.InEpsilon(t, 11, throughput.RequestsPerSecond/throughput.ErrorsPerSecond, 0.01)
+
+			if !test.expectedETA {
+				assert.Nil(t, throughput.ETA)
+				return
+			}
+
+			require.NotNil(t, throughput.ETA)
+			assert.InEpsilon(t, 50/throughput.SmoothedRequestsPerSecond, *throughput.ETA, 0.01)
+
+			// Rates are frozen once the crawler finishes, and there's nothing left to do
+			sm.SetLinksInQueue(0)
+			sm.SetAppState(wcrawler.AppState_Finished)
+			first := sm.Snapshot().Throughput
+			time.Sleep(10 * time.Millisecond)
+			second := sm.Snapshot().Throughput
+
+			assert.Equal(t, first.RequestsPerSecond, second.RequestsPerSecond)
+			require.NotNil(t, second.ETA)
+			assert.Equal(t, 0.0, *second.ETA)
+		})
+	}
+}
+
+func TestStatsThroughputAfterFinished(t *testing.T) {
+	sm := wcrawler.NewStatsCLIOutWriter(&bytes.Buffer{}, false, 10, 5)
+	sm.SetAppState(wcrawler.AppState_Running)
+	for i := 0; i < 10; i++ {
+		sm.AddResponseSample("example.com", 200, wcrawler.ResponseInfo{BytesRead: 100}, nil)
+	}
+	sm.SetAppState(wcrawler.AppState_Finished)
+
+	first := sm.Snapshot().Throughput
+
+	// Drawing the sparkline a while after the crawler finished mustn't move the windows on
+	time.Sleep(1100 * time.Millisecond)
+	sm.RunOutputFlusher()
+
+	second := sm.Snapshot().Throughput
+	assert.Greater(t, first.RequestsPerSecond, 0.0)
+	assert.Equal(t, first, second)
+}
+
+func TestStatsErrorCategories(t *testing.T) {
+	buf := &bytes.Buffer{}
+	sm := wcrawler.NewStatsCLIOutWriter(buf, false, 10, 5)
+
+	sm.AddResponseSample("example.com", 200, wcrawler.ResponseInfo{}, nil)
+	sm.AddResponseSample("example.com", 404, wcrawler.ResponseInfo{}, nil)
+	sm.AddResponseSample("other.com", 0, wcrawler.ResponseInfo{},
+		&wcrawler.FetchError{Category: wcrawler.ErrorCategory_Timeout, Err: fmt.Errorf("timeout")})
+	sm.AddResponseSample("other.com", 0, wcrawler.ResponseInfo{},
+		&wcrawler.FetchError{Category: wcrawler.ErrorCategory_Timeout, Err: fmt.Errorf("timeout")})
+
+	snapshot := sm.Snapshot()
+	assert.Equal(t, map[string]int{"http_4xx": 1, "timeout": 2}, snapshot.ErrorCategories)
+	assert.Equal(t, map[string]int{"http_4xx": 1}, snapshot.Hosts["example.com"].Errors)
+	assert.Equal(t, map[string]int{"timeout": 2}, snapshot.Hosts["other.com"].Errors)
+
+	sm.SetAppState(wcrawler.AppState_Finished)
+	sm.RunOutputFlusher()
+	assert.Contains(t, buf.String(), "Top errors:  timeout: 2  http_4xx: 1")
+}
@@ -0,0 +1,253 @@
+package wcrawler
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// CrawlSummary represents the end-of-crawl report.
+type CrawlSummary struct {
+	StartedAt  time.Time `json:"startedAt"`
+	FinishedAt time.Time `json:"finishedAt"`
+	// DurationSeconds is the time the crawl took, in seconds
+	DurationSeconds float64 `json:"durationSeconds"`
+	StopReason      string  `json:"stopReason"`
+
+	CrawlStats
+
+	SlowestPages []PageSummary `json:"slowestPages"`
+	LargestPages []PageSummary `json:"largestPages"`
+	DeepestPages []PageSummary `json:"deepestPages"`
+}
+
+// CrawlStats represents the number of pages, hosts and bytes of a crawl.
+type CrawlStats struct {
+	// PagesDiscovered includes pages found but not requested (e.g. beyond the max depth)
+	PagesDiscovered int   `json:"pagesDiscovered"`
+	PagesFetched    int   `json:"pagesFetched"`
+	UniqueHosts     int   `json:"uniqueHosts"`
+	BytesDownloaded int64 `json:"bytesDownloaded"`
+
+	// StatusCodes counts the pages per status code received
+	StatusCodes map[int]int `json:"statusCodes"`
+	// ErrorCategories counts the failed requests per category
+	ErrorCategories map[string]int `json:"errorCategories"`
+}
+
+// PageSummary represents a page listed in the CrawlSummary.
+type PageSummary struct {
+	URL        string `json:"url"`
+	StatusCode int    `json:"statusCode"`
+	Depth      int    `json:"depth"`
+	Size       int64  `json:"size"`
+	// TotalTimeSeconds is the time taken to download the whole page, in seconds
+	TotalTimeSeconds float64 `json:"totalTimeSeconds"`
+}
+
+// NewCrawlSummary builds the summary of a crawl from its records.
+// top sets how many pages are listed as slowest, largest and deepest.
+func NewCrawlSummary(rm *RecordManager, startedAt time.Time, finishedAt time.Time, stopReason StopReason, top int) CrawlSummary {
+	s := CrawlSummary{
+		StartedAt:       startedAt,
+		FinishedAt:      finishedAt,
+		DurationSeconds: finishedAt.Sub(startedAt).Seconds(),
+		StopReason:      stopReason.String(),
+		CrawlStats:      NewCrawlStats(rm),
+	}
+
+	pages := []PageSummary{}
+
+	for _, rawURL := range sortedURLs(rm) {
+		r := rm.Records[rawURL]
+		if !fetched(r) {
+			continue
+		}
+
+		page := PageSummary{URL: r.URL, StatusCode: r.StatusCode, Depth: r.Depth, Size: r.Size}
+		if r.Timings != nil {
+			page.TotalTimeSeconds = r.Timings.Total.Seconds()
+		}
+		pages = append(pages, page)
+	}
+
+	s.SlowestPages = topPages(pages, top, func(a, b PageSummary) bool { return a.TotalTimeSeconds > b.TotalTimeSeconds })
+	s.LargestPages = topPages(pages, top, func(a, b PageSummary) bool { return a.Size > b.Size })
+	s.DeepestPages = topPages(pages, top, func(a, b PageSummary) bool { return a.Depth > b.Depth })
+
+	return s
+}
+
+// NewCrawlStats counts the pages, hosts and bytes of a crawl from its records.
+func NewCrawlStats(rm *RecordManager) CrawlStats {
+	s := CrawlStats{
+		PagesDiscovered: len(rm.Records),
+		StatusCodes:     map[int]int{},
+		ErrorCategories: map[string]int{},
+	}
+
+	hosts := map[string]bool{}
+
+	for _, r := range rm.Records {
+		if !fetched(r) {
+			continue
+		}
+
+		s.PagesFetched++
+		s.BytesDownloaded += r.Size
+		hosts[r.Host] = true
+
+		if r.StatusCode != 0 {
+			s.StatusCodes[r.StatusCode]++
+		}
+
+		if r.ErrString != "" || r.StatusCode < 200 || r.StatusCode >= 300 {
+			s.ErrorCategories[recordErrorCategory(r).String()]++
+		}
+	}
+
+	s.UniqueHosts = len(hosts)
+
+	return s
+}
+
+// duration returns the time the crawl took, rounded to the millisecond.
+func (s CrawlSummary) duration() time.Duration {
+	return time.Duration(s.DurationSeconds * float64(time.Second)).Round(time.Millisecond)
+}
+
+// WriteTable writes the summary in a human readable format.
+func (s CrawlSummary) WriteTable(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintf(tw, "Duration:\t%s\n", s.duration())
+	fmt.Fprintf(tw, "Stop reason:\t%s\n", s.stopReasonDescription())
+	fmt.Fprintf(tw, "Pages discovered:\t%d\n", s.PagesDiscovered)
+	fmt.Fprintf(tw, "Pages fetched:\t%d\n", s.PagesFetched)
+	fmt.Fprintf(tw, "Unique hosts:\t%d\n", s.UniqueHosts)
+	fmt.Fprintf(tw, "Bytes downloaded:\t%d\n", s.BytesDownloaded)
+
+	fmt.Fprintf(tw, "\nSTATUS\tPAGES\n")
+	for _, code := range s.sortedStatusCodes() {
+		fmt.Fprintf(tw, "%d\t%d\n", code, s.StatusCodes[code])
+	}
+
+	fmt.Fprintf(tw, "\nERROR CATEGORY\tCOUNT\n")
+	for _, category := range sortedKeys(s.ErrorCategories) {
+		fmt.Fprintf(tw, "%s\t%d\n", category, s.ErrorCategories[category])
+	}
+
+	fmt.Fprintf(tw, "\nSLOWEST\tTIME (s)\tSTATUS\tURL\n")
+	for i, p := range s.SlowestPages {
+		fmt.Fprintf(tw, "%d\t%.3f\t%d\t%s\n", i+1, p.TotalTimeSeconds, p.StatusCode, p.URL)
+	}
+
+	fmt.Fprintf(tw, "\nLARGEST\tSIZE (bytes)\tSTATUS\tURL\n")
+	for i, p := range s.LargestPages {
+		fmt.Fprintf(tw, "%d\t%d\t%d\t%s\n", i+1, p.Size, p.StatusCode, p.URL)
+	}
+
+	fmt.Fprintf(tw, "\nDEEPEST\tDEPTH\tSTATUS\tURL\n")
+	for i, p := range s.DeepestPages {
+		fmt.Fprintf(tw, "%d\t%d\t%d\t%s\n", i+1, p.Depth, p.StatusCode, p.URL)
+	}
+
+	return tw.Flush()
+}
+
+// WriteMarkdown writes the summary as a Markdown document.
+func (s CrawlSummary) WriteMarkdown(w io.Writer) error {
+	var b strings.Builder
+
+	b.WriteString("# Crawl summary\n\n")
+	b.WriteString("| Metric | Value |\n|---|---|\n")
+	fmt.Fprintf(&b, "| Started at | %s |\n", s.StartedAt.Format(time.RFC3339))
+	fmt.Fprintf(&b, "| Finished at | %s |\n", s.FinishedAt.Format(time.RFC3339))
+	fmt.Fprintf(&b, "| Duration | %s |\n", s.duration())
+	fmt.Fprintf(&b, "| Stop reason | %s |\n", s.stopReasonDescription())
+	fmt.Fprintf(&b, "| Pages discovered | %d |\n", s.PagesDiscovered)
+	fmt.Fprintf(&b, "| Pages fetched | %d |\n", s.PagesFetched)
+	fmt.Fprintf(&b, "| Unique hosts | %d |\n", s.UniqueHosts)
+	fmt.Fprintf(&b, "| Bytes downloaded | %d |\n", s.BytesDownloaded)
+
+	b.WriteString("\n## Status codes\n\n| Status | Pages |\n|---|---|\n")
+	for _, code := range s.sortedStatusCodes() {
+		fmt.Fprintf(&b, "| %d | %d |\n", code, s.StatusCodes[code])
+	}
+
+	b.WriteString("\n## Errors\n\n| Category | Count |\n|---|---|\n")
+	for _, category := range sortedKeys(s.ErrorCategories) {
+		fmt.Fprintf(&b, "| %s | %d |\n", category, s.ErrorCategories[category])
+	}
+
+	b.WriteString("\n## Slowest pages\n\n| Time (s) | Status | URL |\n|---|---|---|\n")
+	for _, p := range s.SlowestPages {
+		fmt.Fprintf(&b, "| %.3f | %d | %s |\n", p.TotalTimeSeconds, p.StatusCode, markdownEscape(p.URL))
+	}
+
+	b.WriteString("\n## Largest pages\n\n| Size (bytes) | Status | URL |\n|---|---|---|\n")
+	for _, p := range s.LargestPages {
+		fmt.Fprintf(&b, "| %d | %d | %s |\n", p.Size, p.StatusCode, markdownEscape(p.URL))
+	}
+
+	b.WriteString("\n## Deepest pages\n\n| Depth | Status | URL |\n|---|---|---|\n")
+	for _, p := range s.DeepestPages {
+		fmt.Fprintf(&b, "| %d | %d | %s |\n", p.Depth, p.StatusCode, markdownEscape(p.URL))
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func (s CrawlSummary) stopReasonDescription() string {
+	var reason StopReason
+	reason.Parse(s.StopReason)
+	return reason.Description()
+}
+
+func (s CrawlSummary) sortedStatusCodes() []int {
+	codes := make([]int, 0, len(s.StatusCodes))
+	for code := range s.StatusCodes {
+		codes = append(codes, code)
+	}
+	sort.Ints(codes)
+
+	return codes
+}
+
+// topPages returns the first n pages according to the less function.
+// The sort is stable so ties keep the pages sorted by URL.
+func topPages(pages []PageSummary, n int, less func(a, b PageSummary) bool) []PageSummary {
+	sorted := make([]PageSummary, len(pages))
+	copy(sorted, pages)
+	sort.SliceStable(sorted, func(i, j int) bool { return less(sorted[i], sorted[j]) })
+
+	if n >= 0 && n < len(sorted) {
+		sorted = sorted[:n]
+	}
+
+	return sorted
+}
+
+// recordErrorCategory returns the category of the failure of the request for the record.
+// Records saved before categories were introduced are classified from their status code and error message.
+func recordErrorCategory(r Record) ErrorCategory {
+	if r.ErrorCategory != ErrorCategory_None {
+		return r.ErrorCategory
+	}
+
+	if r.ErrString != "" {
+		return ClassifyError(r.StatusCode, errors.New(r.ErrString))
+	}
+
+	return ClassifyError(r.StatusCode, nil)
+}
+
+// markdownEscape escapes the characters that would break a Markdown table cell.
+func markdownEscape(s string) string {
+	return strings.ReplaceAll(s, "|", `\|`)
+}
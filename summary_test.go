@@ -0,0 +1,114 @@
+package wcrawler_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/gustavooferreira/wcrawler"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newSummaryRecordManager() *wcrawler.RecordManager {
+	rm := wcrawler.NewRecordManager()
+	rm.AddRecord(wcrawler.RMEntry{URL: wcrawler.URLEntity{NetLoc: "example.com", Raw: "http://example.com"}})
+	rm.AddRecord(wcrawler.RMEntry{ParentURL: "http://example.com",
+		URL: wcrawler.URLEntity{NetLoc: "example.com", Raw: "http://example.com/big"}, Depth: 1})
+	rm.AddRecord(wcrawler.RMEntry{ParentURL: "http://example.com",
+		URL: wcrawler.URLEntity{NetLoc: "other.com", Raw: "http://other.com/slow"}, Depth: 1})
+	rm.AddRecord(wcrawler.RMEntry{ParentURL: "http://other.com/slow",
+		URL: wcrawler.URLEntity{NetLoc: "other.com", Raw: "http://other.com/deep"}, Depth: 2})
+	rm.AddRecord(wcrawler.RMEntry{ParentURL: "http://other.com/deep",
+		URL: wcrawler.URLEntity{NetLoc: "other.com", Raw: "http://other.com/unvisited"}, Depth: 3})
+
+	rm.Update("http://example.com", 200, nil)
+	rm.SetResponseInfo("http://example.com", wcrawler.ResponseInfo{BytesRead: 100,
+		Timings: wcrawler.Timings{Total: 10 * time.Millisecond}})
+	rm.Update("http://example.com/big", 200, nil)
+	rm.SetResponseInfo("http://example.com/big", wcrawler.ResponseInfo{BytesRead: 5000,
+		Timings: wcrawler.Timings{Total: 20 * time.Millisecond}})
+	rm.Update("http://other.com/slow", 500, nil)
+	rm.SetResponseInfo("http://other.com/slow", wcrawler.ResponseInfo{BytesRead: 50,
+		Timings: wcrawler.Timings{Total: 2 * time.Second}})
+	rm.Update("http://other.com/deep", 0, errTimeout{})
+
+	return rm
+}
+
+type errTimeout struct{}
+
+func (errTimeout) Error() string { return "Client.Timeout exceeded while awaiting headers" }
+
+func TestNewCrawlSummary(t *testing.T) {
+	rm := newSummaryRecordManager()
+	start := time.Date(2021, 1, 1, 10, 0, 0, 0, time.UTC)
+
+	summary := wcrawler.NewCrawlSummary(rm, start, start.Add(3*time.Second), wcrawler.StopReason_MaxDepth, 2)
+
+	assert.Equal(t, 3.0, summary.DurationSeconds)
+	assert.Equal(t, "MaxDepth", summary.StopReason)
+	assert.Equal(t, 5, summary.PagesDiscovered)
+	assert.Equal(t, 4, summary.PagesFetched)
+	assert.Equal(t, 2, summary.UniqueHosts)
+	assert.Equal(t, int64(5150), summary.BytesDownloaded)
+	assert.Equal(t, map[int]int{200: 2, 500: 1}, summary.StatusCodes)
+	assert.Equal(t, map[string]int{"http_5xx": 1, "timeout": 1}, summary.ErrorCategories)
+
+	require.Len(t, summary.SlowestPages, 2)
+	assert.Equal(t, "http://other.com/slow", summary.SlowestPages[0].URL)
+	assert.Equal(t, 2.0, summary.SlowestPages[0].TotalTimeSeconds)
+	assert.Equal(t, "http://example.com/big", summary.SlowestPages[1].URL)
+
+	require.Len(t, summary.LargestPages, 2)
+	assert.Equal(t, "http://example.com/big", summary.LargestPages[0].URL)
+	assert.Equal(t, int64(5000), summary.LargestPages[0].Size)
+
+	require.Len(t, summary.DeepestPages, 2)
+	assert.Equal(t, "http://other.com/deep", summary.DeepestPages[0].URL)
+	assert.Equal(t, 2, summary.DeepestPages[0].Depth)
+}
+
+func TestCrawlSummaryWriters(t *testing.T) {
+	rm := newSummaryRecordManager()
+	start := time.Date(2021, 1, 1, 10, 0, 0, 0, time.UTC)
+	summary := wcrawler.NewCrawlSummary(rm, start, start.Add(3*time.Second), wcrawler.StopReason_Completed, 1)
+
+	var table bytes.Buffer
+	err := summary.WriteTable(&table)
+	require.NoError(t, err)
+	assert.Contains(t, table.String(), "all links found were visited")
+	assert.Regexp(t, `Duration:\s+3s\n`, table.String())
+	assert.Contains(t, table.String(), "http://other.com/slow")
+
+	var md bytes.Buffer
+	err = summary.WriteMarkdown(&md)
+	require.NoError(t, err)
+	assert.Contains(t, md.String(), "# Crawl summary")
+	assert.Contains(t, md.String(), "| 500 | 1 |")
+	assert.Contains(t, md.String(), "| 2.000 | 500 | http://other.com/slow |")
+}
+
+func TestCrawlSummaryJSON(t *testing.T) {
+	rm := newSummaryRecordManager()
+	start := time.Date(2021, 1, 1, 10, 0, 0, 0, time.UTC)
+	summary := wcrawler.NewCrawlSummary(rm, start, start.Add(3500*time.Millisecond), wcrawler.StopReason_Completed, 1)
+
+	data, err := json.Marshal(summary)
+	require.NoError(t, err)
+
+	// Durations are in seconds, not nanoseconds
+	var report struct {
+		DurationSeconds float64 `json:"durationSeconds"`
+		SlowestPages    []struct {
+			TotalTimeSeconds float64 `json:"totalTimeSeconds"`
+		} `json:"slowestPages"`
+	}
+	require.NoError(t, json.Unmarshal(data, &report))
+	assert.Equal(t, 3.5, report.DurationSeconds)
+	require.Len(t, report.SlowestPages, 1)
+	assert.Equal(t, 2.0, report.SlowestPages[0].TotalTimeSeconds)
+	assert.NotContains(t, string(data), `"duration"`)
+	assert.NotContains(t, string(data), `"totalTime"`)
+}
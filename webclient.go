@@ -1,14 +1,21 @@
 package wcrawler
 
 import (
+	"crypto/tls"
 	"io"
+	"mime"
 	"net/http"
 	"net/http/httptrace"
+	"strings"
+	"sync"
 	"time"
 
 	"golang.org/x/net/html"
 )
 
+// maxAnchorTextLength is the max number of characters kept from the text of a link.
+const maxAnchorTextLength = 100
+
 // WebClient is responsible to connect to the links and manage connections to websites.
 // Implements Connector interface.
 type WebClient struct {
@@ -21,42 +28,137 @@ func NewWebClient(client *http.Client) *WebClient {
 }
 
 // GetLinks returns all the links found in the webpage.
-func (c *WebClient) GetLinks(rawURL string) (statusCode int, links []URLEntity, latency time.Duration, err error) {
+func (c *WebClient) GetLinks(rawURL string) (statusCode int, links []URLEntity, info ResponseInfo, err error) {
 	// make sure to use the same http.Client to reuse connections to get links
 	// from other pages being served by the same server.
 	// Check for robot.txt, maybe?
 
 	req, err := http.NewRequest("GET", rawURL, nil)
 	if err != nil {
-		return 0, links, latency, err
+		return 0, links, info, err
 	}
 
-	var start time.Time
-
-	trace := &httptrace.ClientTrace{
-		GotFirstResponseByte: func() {
-			latency = time.Since(start)
-		},
-	}
+	tracer := &timingsTracer{}
 
-	req = req.WithContext(httptrace.WithClientTrace(req.Context(), trace))
-	start = time.Now()
+	req = req.WithContext(httptrace.WithClientTrace(req.Context(), tracer.clientTrace()))
+	tracer.start = time.Now()
+	defer func() {
+		info.Timings = tracer.timings(time.Now())
+	}()
 
 	resp, err := c.client.Do(req)
 	if err != nil {
-		return 0, links, latency, err
+		return 0, links, info, err
 	}
 	defer resp.Body.Close()
 
 	statusCode = resp.StatusCode
+	if mediaType, _, err := mime.ParseMediaType(resp.Header.Get("Content-Type")); err == nil {
+		info.ContentType = mediaType
+	}
 
 	if statusCode < 200 || statusCode >= 300 {
-		return statusCode, links, latency, nil
+		return statusCode, links, info, nil
 	}
 
-	links, err = c.parse(rawURL, resp.Body)
+	body := &countingReader{reader: resp.Body}
+	links, err = c.parse(rawURL, body)
+	info.BytesRead = body.count
 
-	return statusCode, links, latency, err
+	if err != nil {
+		// Reading the body can fail for the same reasons as the request (e.g. a timeout)
+		category := ClassifyError(0, err)
+		if category == ErrorCategory_Other {
+			category = ErrorCategory_Parse
+		}
+		err = &FetchError{Category: category, Err: err}
+	}
+
+	return statusCode, links, info, err
+}
+
+// timingsTracer keeps track of the time spent in each phase of a request using httptrace hooks.
+// Hooks might be called from other goroutines (e.g. while dialing), hence the mutex.
+type timingsTracer struct {
+	start time.Time
+
+	mu           sync.Mutex
+	dnsStart     time.Time
+	dnsDone      time.Time
+	connectStart time.Time
+	connectDone  time.Time
+	tlsStart     time.Time
+	tlsDone      time.Time
+	firstByte    time.Time
+	connReused   bool
+}
+
+// clientTrace returns the httptrace hooks recording the time of each event.
+func (tt *timingsTracer) clientTrace() *httptrace.ClientTrace {
+	record := func(t *time.Time, onlyFirst bool) {
+		tt.mu.Lock()
+		defer tt.mu.Unlock()
+		if onlyFirst && !t.IsZero() {
+			return
+		}
+		*t = time.Now()
+	}
+
+	return &httptrace.ClientTrace{
+		DNSStart:             func(httptrace.DNSStartInfo) { record(&tt.dnsStart, true) },
+		DNSDone:              func(httptrace.DNSDoneInfo) { record(&tt.dnsDone, false) },
+		ConnectStart:         func(string, string) { record(&tt.connectStart, true) },
+		ConnectDone:          func(string, string, error) { record(&tt.connectDone, false) },
+		TLSHandshakeStart:    func() { record(&tt.tlsStart, true) },
+		TLSHandshakeDone:     func(tls.ConnectionState, error) { record(&tt.tlsDone, false) },
+		GotFirstResponseByte: func() { record(&tt.firstByte, true) },
+		GotConn: func(info httptrace.GotConnInfo) {
+			tt.mu.Lock()
+			defer tt.mu.Unlock()
+			tt.connReused = info.Reused
+		},
+	}
+}
+
+// timings returns the time spent in each phase, considering the request finished at 'end'.
+func (tt *timingsTracer) timings(end time.Time) Timings {
+	tt.mu.Lock()
+	defer tt.mu.Unlock()
+
+	t := Timings{
+		DNSLookup:    elapsed(tt.dnsStart, tt.dnsDone),
+		TCPConnect:   elapsed(tt.connectStart, tt.connectDone),
+		TLSHandshake: elapsed(tt.tlsStart, tt.tlsDone),
+		TTFB:         elapsed(tt.start, tt.firstByte),
+		Total:        end.Sub(tt.start),
+		ConnReused:   tt.connReused,
+	}
+
+	if !tt.firstByte.IsZero() {
+		t.ContentTransfer = end.Sub(tt.firstByte)
+	}
+
+	return t
+}
+
+// elapsed returns the time between start and end (zero if either of them didn't happen).
+func elapsed(start time.Time, end time.Time) time.Duration {
+	if start.IsZero() || end.IsZero() {
+		return 0
+	}
+	return end.Sub(start)
+}
+
+// countingReader wraps a reader and counts the number of bytes read.
+type countingReader struct {
+	reader io.Reader
+	count  int64
+}
+
+func (cr *countingReader) Read(p []byte) (int, error) {
+	n, err := cr.reader.Read(p)
+	cr.count += int64(n)
+	return n, err
 }
 
 // parse parses the webpage looking for links.
@@ -74,6 +176,18 @@ func (c *WebClient) parse(rawURL string, r io.Reader) (links []URLEntity, err er
 
 	links = []URLEntity{}
 
+	// Keeps track of the link whose anchor text is being collected (-1 if none)
+	anchorIndex := -1
+	var anchorText strings.Builder
+
+	closeAnchor := func() {
+		if anchorIndex != -1 {
+			links[anchorIndex].AnchorText = normalizeAnchorText(anchorText.String())
+			anchorIndex = -1
+		}
+		anchorText.Reset()
+	}
+
 	z := html.NewTokenizer(r)
 
 	for {
@@ -81,8 +195,16 @@ func (c *WebClient) parse(rawURL string, r io.Reader) (links []URLEntity, err er
 
 		switch {
 		case tt == html.ErrorToken:
-			// EOF
+			closeAnchor()
+			// io.EOF is the end of the page, anything else failed reading it (e.g. a timeout or reset)
+			if err := z.Err(); err != io.EOF {
+				return links, err
+			}
 			return links, nil
+		case tt == html.TextToken:
+			if anchorIndex != -1 {
+				anchorText.Write(z.Text())
+			}
 		case tt == html.StartTagToken || tt == html.SelfClosingTagToken:
 			t := z.Token()
 
@@ -105,6 +227,9 @@ func (c *WebClient) parse(rawURL string, r io.Reader) (links []URLEntity, err er
 				continue
 			}
 
+			// <a> tags cannot be nested, so a new one closes any previous one left open
+			closeAnchor()
+
 			// Extract the href value, if there is one
 			ok, rawURL := getHref(t)
 			if !ok {
@@ -121,16 +246,36 @@ func (c *WebClient) parse(rawURL string, r io.Reader) (links []URLEntity, err er
 
 			links = append(links, urlEntity)
 
+			if tt == html.StartTagToken {
+				anchorIndex = len(links) - 1
+			}
+
 		case tt == html.EndTagToken:
 			t := z.Token()
 			if t.Data == "head" {
 				insideHead = false
 			}
 
+			if t.Data == "a" {
+				closeAnchor()
+			}
+
 		}
 	}
 }
 
+// normalizeAnchorText collapses whitespace in the anchor text and truncates it to maxAnchorTextLength runes.
+func normalizeAnchorText(text string) string {
+	text = strings.Join(strings.Fields(text), " ")
+
+	runes := []rune(text)
+	if len(runes) > maxAnchorTextLength {
+		text = string(runes[:maxAnchorTextLength]) + "..."
+	}
+
+	return text
+}
+
 // getHref returns the href attribute from a Token.
 func getHref(t html.Token) (ok bool, href string) {
 	// Iterate over all of the Token's attributes until it finds an "href"
@@ -27,3 +27,20 @@ const htmlBody2 = `
 </body>
 </html>
 `
+
+const htmlBody3 = `
+<!DOCTYPE html>
+<html>
+<head>
+<title>Title</title>
+</head>
+<body>
+<a href="http://www.example.com/about">
+  <span>About</span>
+  us
+</a>
+<p>Not part of the link</p>
+<a href="http://www.example.com/contact"><img src="contact.png"/></a>
+</body>
+</html>
+`
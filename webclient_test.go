@@ -7,6 +7,7 @@ import (
 	"net/url"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/gustavooferreira/wcrawler"
 	"github.com/stretchr/testify/assert"
@@ -26,14 +27,17 @@ func TestWebClient(t *testing.T) {
 			htmlBody:           htmlBody1,
 			expectedStatusCode: 200,
 			expectedLinks: []wcrawler.URLEntity{{
-				NetLoc: "www.example.com",
-				Raw:    "http://www.example.com/file.html",
+				NetLoc:     "www.example.com",
+				Raw:        "http://www.example.com/file.html",
+				AnchorText: "link1",
 			}, {
-				NetLoc: "%s",
-				Raw:    "%s/path/to/file999",
+				NetLoc:     "%s",
+				Raw:        "%s/path/to/file999",
+				AnchorText: "link1",
 			}, {
-				NetLoc: "%s",
-				Raw:    "%s/random/path/to/oblivion/path/to/file2",
+				NetLoc:     "%s",
+				Raw:        "%s/random/path/to/oblivion/path/to/file2",
+				AnchorText: "link1",
 			}},
 			expectedErr: false,
 		},
@@ -42,11 +46,28 @@ func TestWebClient(t *testing.T) {
 			htmlBody:           htmlBody2,
 			expectedStatusCode: 200,
 			expectedLinks: []wcrawler.URLEntity{{
-				NetLoc: "www.example.com",
-				Raw:    "http://www.example.com/path/to/file1",
+				NetLoc:     "www.example.com",
+				Raw:        "http://www.example.com/path/to/file1",
+				AnchorText: "link1",
 			}, {
-				NetLoc: "www.example.com",
-				Raw:    "http://www.example.com/base/path/to/dir/relative/file2",
+				NetLoc:     "www.example.com",
+				Raw:        "http://www.example.com/base/path/to/dir/relative/file2",
+				AnchorText: "link1",
+			}},
+			expectedErr: false,
+		},
+		"parse anchor text": {
+			path:               "/index.html",
+			htmlBody:           htmlBody3,
+			expectedStatusCode: 200,
+			expectedLinks: []wcrawler.URLEntity{{
+				NetLoc:     "www.example.com",
+				Raw:        "http://www.example.com/about",
+				AnchorText: "About us",
+			}, {
+				NetLoc:     "www.example.com",
+				Raw:        "http://www.example.com/contact",
+				AnchorText: "",
 			}},
 			expectedErr: false,
 		},
@@ -72,7 +93,7 @@ func TestWebClient(t *testing.T) {
 
 			host := u.Host
 
-			statusCode, links, _, err := wc.GetLinks(queryURL)
+			statusCode, links, info, err := wc.GetLinks(queryURL)
 
 			if test.expectedErr {
 				require.Error(t, err)
@@ -93,6 +114,83 @@ func TestWebClient(t *testing.T) {
 
 			assert.Equal(t, test.expectedStatusCode, statusCode)
 			assert.Equal(t, test.expectedLinks, links)
+			assert.Equal(t, int64(len(test.htmlBody)+1), info.BytesRead)
+		})
+	}
+}
+
+func TestWebClientTimings(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintln(w, `<html><body><a href="/about">About</a></body></html>`)
+	}))
+	defer ts.Close()
+
+	wc := wcrawler.NewWebClient(&http.Client{})
+
+	_, _, info, err := wc.GetLinks(ts.URL)
+	require.NoError(t, err)
+
+	// The content type is sniffed by the server, the parameters (charset) are dropped
+	assert.Equal(t, "text/html", info.ContentType)
+
+	timings := info.Timings
+	assert.Equal(t, false, timings.ConnReused)
+	assert.Greater(t, int64(timings.TCPConnect), int64(0))
+	assert.Greater(t, int64(timings.TTFB), int64(0))
+	assert.GreaterOrEqual(t, int64(timings.Total), int64(timings.TTFB))
+	assert.Equal(t, timings.Total-timings.TTFB, timings.ContentTransfer)
+
+	// The second request goes through the same (kept alive) connection
+	_, _, info, err = wc.GetLinks(ts.URL)
+	require.NoError(t, err)
+
+	assert.Equal(t, true, info.Timings.ConnReused)
+	assert.Equal(t, time.Duration(0), info.Timings.TCPConnect)
+}
+
+func TestWebClientBodyErrors(t *testing.T) {
+	page := `<html><body><a href="/about">About</a>`
+
+	tests := map[string]struct {
+		handler          http.HandlerFunc
+		expectedCategory wcrawler.ErrorCategory
+	}{
+		"truncated body": {
+			// The connection is closed before the whole body announced is sent
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Length", "1000")
+				fmt.Fprint(w, page)
+			},
+			expectedCategory: wcrawler.ErrorCategory_Connection,
+		},
+		"stalled body": {
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				fmt.Fprint(w, page)
+				w.(http.Flusher).Flush()
+				<-r.Context().Done()
+			},
+			expectedCategory: wcrawler.ErrorCategory_Timeout,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			ts := httptest.NewServer(test.handler)
+			defer ts.Close()
+
+			wc := wcrawler.NewWebClient(&http.Client{Timeout: 200 * time.Millisecond})
+
+			statusCode, links, _, err := wc.GetLinks(ts.URL)
+			require.Error(t, err)
+
+			var fetchErr *wcrawler.FetchError
+			require.ErrorAs(t, err, &fetchErr)
+			assert.Equal(t, test.expectedCategory, fetchErr.Category)
+
+			// The links read before the failure are kept
+			assert.Equal(t, 200, statusCode)
+			require.Len(t, links, 1)
+			assert.Equal(t, ts.URL+"/about", links[0].Raw)
 		})
 	}
 }